@@ -1,8 +1,11 @@
 package cmd
 
 import (
-	"bytes"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gojek/optimus-extension-valor/core"
 	"github.com/gojek/optimus-extension-valor/model"
@@ -15,27 +18,56 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const defaultProgressType = "progressive"
+const (
+	defaultProgressType = "progressive"
+	warningWriterType   = "std"
+)
 
-var progressType string
+var (
+	progressType string
+	dryRun       bool
+)
 
 func getExecuteCmd() *cobra.Command {
 	runCmd := &cobra.Command{
 		Use:   "execute",
 		Short: "Execute pipeline based on the specified recipe",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return executePipeline(recipePath, progressType, enrichWithBatchSize)
+			enrich := func(rcp *recipe.Recipe) error {
+				if err := enrichWithBatchSize(rcp); err != nil {
+					return err
+				}
+				return enrichWithTags(rcp)
+			}
+			return executePipeline(recipePath, progressType, enrich)
 		},
 	}
 	runCmd.PersistentFlags().StringVarP(&recipePath, "recipe-path", "R", defaultRecipePath, "Path of the recipe file")
+	runCmd.PersistentFlags().StringVar(&recipeFormat, "recipe-format", "", "Format of the recipe file, one of: yaml, json, jsonnet. Detected from the path extension if not specified")
 	runCmd.PersistentFlags().StringVarP(&progressType, "progress-type", "P", defaultProgressType, "Progress type to be used")
+	runCmd.PersistentFlags().StringArrayVar(&setValues, "set", nil, "Parameter to be interpolated in the recipe, in the form of key=value")
+	runCmd.PersistentFlags().StringSliceVar(&tags, "tags", nil, "Only execute resources and frameworks having at least one of the tags")
+	runCmd.PersistentFlags().StringSliceVar(&skipTags, "skip-tags", nil, "Skip resources and frameworks having any of the tags")
+	runCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print which files would be evaluated and where the output would be written, without evaluating nor writing anything")
 
 	runCmd.AddCommand(getResourceCmd())
 	return runCmd
 }
 
 func executePipeline(recipePath, progressType string, enrich func(*recipe.Recipe) error) error {
-	rcp, err := loadRecipe(recipePath, defaultRecipeType, defaultRecipeFormat)
+	err := runPipeline(recipePath, progressType, enrich)
+	if e, ok := err.(*model.Error); ok {
+		return errors.New(string(e.JSON()))
+	}
+	return err
+}
+
+func runPipeline(recipePath, progressType string, enrich func(*recipe.Recipe) error) error {
+	params, err := parseSetValues(setValues)
+	if err != nil {
+		return err
+	}
+	rcp, err := loadRecipe(recipePath, defaultRecipeType, recipeFormat, params)
 	if err != nil {
 		return err
 	}
@@ -44,51 +76,242 @@ func executePipeline(recipePath, progressType string, enrich func(*recipe.Recipe
 			return err
 		}
 	}
-	if err := recipe.Validate(rcp); err != nil {
+	if err := recipe.ValidateDeep(rcp); err != nil {
+		return err
+	}
+	if err := recipe.ResolveExtends(rcp); err != nil {
+		return err
+	}
+	if err := recipe.ResolveOutputRefs(rcp); err != nil {
 		return err
 	}
 	newProgress, err := progress.Progresses.Get(progressType)
 	if err != nil {
 		return err
 	}
-	evaluate := getEvaluate()
-	pipeline, err := core.NewPipeline(rcp, evaluate, newProgress)
+	evaluate, addFile, err := getEvaluate(rcp.Jsonnet)
 	if err != nil {
 		return err
 	}
-	err = pipeline.Execute()
-	if e, ok := err.(*model.Error); ok {
-		return errors.New(string(e.JSON()))
+	pipeline, err := core.NewPipeline(rcp, evaluate, addFile, newProgress)
+	if err != nil {
+		return err
 	}
-	return err
+	if dryRun {
+		plan, err := pipeline.Plan()
+		if err != nil {
+			return err
+		}
+		printPlan(plan)
+		return nil
+	}
+	return pipeline.Execute()
 }
 
-func getEvaluate() model.Evaluate {
-	vm := jsonnet.MakeVM()
-	return func(name, snippet string) (string, error) {
-		return vm.EvaluateAnonymousSnippet(name, snippet)
+func printPlan(plan *model.Plan) {
+	for _, resource := range plan.Resources {
+		fmt.Printf("resource [%s] with %d file(s)\n", resource.Name, len(resource.Paths))
+		for _, p := range resource.Paths {
+			fmt.Printf("  %s\n", p)
+		}
+		for _, framework := range resource.Frameworks {
+			fmt.Printf("  framework [%s]\n", framework.Name)
+			for _, definition := range framework.Definitions {
+				fmt.Printf("    definition [%s] with %d file(s)\n", definition.Name, len(definition.Paths))
+				for _, p := range definition.Paths {
+					fmt.Printf("      %s\n", p)
+				}
+				if definition.FunctionPath != "" {
+					fmt.Printf("      function: %s\n", definition.FunctionPath)
+				}
+			}
+			for _, schema := range framework.Schemas {
+				printStepPlan("schema", schema)
+			}
+			for _, procedure := range framework.Procedures {
+				printStepPlan("procedure", procedure)
+			}
+		}
+	}
+}
+
+func printStepPlan(kind string, step *model.StepPlan) {
+	fmt.Printf("    %s [%s]: %s\n", kind, step.Name, step.Path)
+	if step.When != "" {
+		fmt.Printf("      when: %s\n", step.When)
+	}
+	for _, t := range step.Targets {
+		fmt.Printf("      -> target [%s] (%s, %s, %s)\n", t.Name, step.TreatAs, t.Type, t.Format)
+		for _, d := range t.Destinations {
+			fmt.Printf("         %s\n", d)
+		}
 	}
 }
 
-func loadRecipe(path, _type, format string) (*recipe.Recipe, error) {
+// getEvaluate gets Evaluate backed by a pool of identically configured VMs, along with AddFile of the importer
+// shared by them, where import is relative to the evaluated path first, then to the library paths in the order they are specified
+func getEvaluate(jsonnetRcp *recipe.Jsonnet) (model.Evaluate, model.AddFile, error) {
+	var libraryPaths []string
+	var poolSize int
+	if jsonnetRcp != nil {
+		libraryPaths = jsonnetRcp.LibraryPaths
+		poolSize = jsonnetRcp.PoolSize
+	}
+	importer := core.NewImporter(libraryPaths)
+	pool, err := core.NewVMPool(poolSize, func() *jsonnet.VM {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		core.RegisterNativeFunctions(vm)
+		return vm
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool.Evaluate, importer.Add, nil
+}
+
+func loadRecipe(path, _type, format string, params map[string]string) (*recipe.Recipe, error) {
+	_type, path = parseRecipePath(path, _type)
 	fnReader, err := io.Readers.Get(_type)
 	if err != nil {
 		return nil, err
 	}
-	getPath := func() string {
-		return path
+	// stdin can only be read once, so its included recipes are read with the default type
+	includeType := _type
+	if _type == stdinRecipeType {
+		includeType = defaultRecipeType
+	}
+	fnIncludeReader, err := io.Readers.Get(includeType)
+	if err != nil {
+		return nil, err
+	}
+	getDecode := func(p string) (model.Decode, error) {
+		recipeFormat := getRecipeFormat(p, format)
+		if recipeFormat == jsonnetRecipeFormat {
+			recipeFormat = jsonRecipeFormat
+		}
+		return endec.Decodes.Get(recipeFormat)
+	}
+	newReader := func(p string) model.Reader {
+		getPath := func() string {
+			return p
+		}
+		postProcess := func(p string, c []byte) (*model.Data, error) {
+			recipeFormat := getRecipeFormat(p, format)
+			if recipeFormat == jsonnetRecipeFormat {
+				evaluated, err := evaluateRecipe(p, c)
+				if err != nil {
+					return nil, err
+				}
+				c = evaluated
+			}
+			decode, err := getDecode(p)
+			if err != nil {
+				return nil, err
+			}
+			if err := warnLegacyCase(p, c, decode); err != nil {
+				return nil, err
+			}
+			if err := warnOutdatedVersion(p, recipeFormat, c); err != nil {
+				return nil, err
+			}
+			return &model.Data{
+				Content: c,
+				Path:    p,
+				Type:    recipeFormat,
+			}, nil
+		}
+		if p != path {
+			return fnIncludeReader(getPath, postProcess)
+		}
+		return fnReader(getPath, postProcess)
 	}
-	postProcess := func(p string, c []byte) (*model.Data, error) {
-		return &model.Data{
-			Content: bytes.ToLower(c),
-			Path:    p,
-			Type:    format,
-		}, nil
+	// every recipe is interpolated as it is loaded, so an included path could refer to a variable
+	interpolate := func(rcp *recipe.Recipe) error {
+		return recipe.Interpolate(rcp, params, os.LookupEnv)
 	}
-	reader := fnReader(getPath, postProcess)
-	decode, err := endec.Decodes.Get(format)
+	rcp, err := recipe.LoadAll(path, newReader, getDecode, interpolate)
 	if err != nil {
 		return nil, err
 	}
-	return recipe.Load(reader, decode)
+	recipe.Normalize(rcp)
+	return rcp, nil
+}
+
+// getRecipeFormat gets the format of recipe in the path, where the specified format
+// takes priority over the one detected from the path extension
+func getRecipeFormat(path, format string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if output, ok := extensionToRecipeFormat[strings.ToLower(filepath.Ext(path))]; ok {
+		return output
+	}
+	return defaultRecipeFormat
+}
+
+// evaluateRecipe evaluates Jsonnet recipe into JSON, where import is relative to the recipe path
+func evaluateRecipe(path string, content []byte) ([]byte, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{
+		JPaths: []string{filepath.Dir(path)},
+	})
+	output, err := vm.EvaluateAnonymousSnippet(path, string(content))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
+
+func warnLegacyCase(path string, content []byte, decode model.Decode) error {
+	warnings, err := recipe.DetectLegacyCase(content, decode)
+	if err != nil {
+		return err
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("recipe is no longer lowercased during load, please review the following fields:\n%s",
+		strings.Join(warnings, "\n"),
+	)
+	return writeWarning(path, message)
+}
+
+// warnOutdatedVersion warns if the recipe is not at the current version, where the migrate
+// command is only suggested for the format it supports
+func warnOutdatedVersion(path, format string, content []byte) error {
+	message, err := buildOutdatedVersionMessage(path, format, content)
+	if err != nil || message == "" {
+		return nil
+	}
+	return writeWarning(path, message)
+}
+
+func buildOutdatedVersionMessage(path, format string, content []byte) (string, error) {
+	version, err := recipe.DetectAPIVersion(content)
+	if err != nil || version == recipe.CurrentAPIVersion {
+		return "", err
+	}
+	if version == recipe.LegacyAPIVersion {
+		version = "unversioned"
+	}
+	message := fmt.Sprintf("recipe is [%s] and migrated in memory to [%s]", version, recipe.CurrentAPIVersion)
+	if format == defaultRecipeFormat {
+		message += fmt.Sprintf(", run `valor recipe migrate -R %s` to update it", path)
+	} else {
+		message += fmt.Sprintf(", please update it manually since `valor recipe migrate` only supports [%s]", defaultRecipeFormat)
+	}
+	return message, nil
+}
+
+func writeWarning(path, message string) error {
+	writerFn, err := io.Writers.Get(warningWriterType)
+	if err != nil {
+		return err
+	}
+	return writerFn(model.TreatmentWarning).Write(&model.Data{
+		Type:    warningWriterType,
+		Path:    path,
+		Content: []byte(message),
+	})
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildOutdatedVersionMessage(t *testing.T) {
+	t.Run("should return empty if recipe is at current version", func(t *testing.T) {
+		actualMessage, actualErr := buildOutdatedVersionMessage("valor.yaml", "yaml", []byte("apiVersion: v1\n"))
+
+		assert.Empty(t, actualMessage)
+		assert.Nil(t, actualErr)
+	})
+
+	t.Run("should suggest migrate command if recipe is yaml", func(t *testing.T) {
+		expectedMessage := "recipe is [unversioned] and migrated in memory to [v1], run `valor recipe migrate -R valor.yaml` to update it"
+
+		actualMessage, actualErr := buildOutdatedVersionMessage("valor.yaml", "yaml", []byte("resources: []\n"))
+
+		assert.Equal(t, expectedMessage, actualMessage)
+		assert.Nil(t, actualErr)
+	})
+
+	t.Run("should not suggest migrate command if recipe is not yaml", func(t *testing.T) {
+		for _, format := range []string{"json", "jsonnet"} {
+			actualMessage, actualErr := buildOutdatedVersionMessage("valor."+format, format, []byte(`{"resources": []}`))
+
+			assert.NotContains(t, actualMessage, "valor recipe migrate -R", format)
+			assert.Contains(t, actualMessage, "update it manually", format)
+			assert.Nil(t, actualErr)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	graphOutputDOT     = "dot"
+	graphOutputMermaid = "mermaid"
+)
+
+func getGraphCmd() *cobra.Command {
+	var output string
+	runCmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the graph of resources, frameworks, their steps, and output targets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := parseSetValues(setValues)
+			if err != nil {
+				return err
+			}
+			rcp, err := loadRecipe(recipePath, defaultRecipeType, recipeFormat, params)
+			if err != nil {
+				return err
+			}
+			if err := enrichWithTags(rcp); err != nil {
+				return err
+			}
+			if err := recipe.ResolveExtends(rcp); err != nil {
+				return err
+			}
+			if err := recipe.ResolveOutputRefs(rcp); err != nil {
+				return err
+			}
+			// only frameworks used by the selected resources are shown when tags are specified
+			if len(tags) > 0 || len(skipTags) > 0 {
+				recipe.SelectUsedFrameworks(rcp)
+			}
+			graph, err := recipe.BuildGraph(rcp)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(output) {
+			case graphOutputDOT:
+				fmt.Println(graph.DOT())
+			case graphOutputMermaid:
+				fmt.Println(graph.Mermaid())
+			default:
+				return fmt.Errorf("output [%s] is not supported", output)
+			}
+			return nil
+		},
+	}
+	runCmd.PersistentFlags().StringVarP(&recipePath, "recipe-path", "R", defaultRecipePath, "Path of the recipe file")
+	runCmd.PersistentFlags().StringVar(&recipeFormat, "recipe-format", "", "Format of the recipe file, one of: yaml, json, jsonnet. Detected from the path extension if not specified")
+	runCmd.PersistentFlags().StringArrayVar(&setValues, "set", nil, "Parameter to be interpolated in the recipe, in the form of key=value")
+	runCmd.PersistentFlags().StringSliceVar(&tags, "tags", nil, "Only include resources and frameworks having at least one of the tags")
+	runCmd.PersistentFlags().StringSliceVar(&skipTags, "skip-tags", nil, "Skip resources and frameworks having any of the tags")
+	runCmd.PersistentFlags().StringVarP(&output, "output", "o", graphOutputDOT, "Output format, one of: dot, mermaid")
+	return runCmd
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+	"github.com/gojek/optimus-extension-valor/registry/io"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	templateFull           = "full"
+	templateJSONSchemaOnly = "json-schema-only"
+	templateProcedureOnly  = "procedure-only"
+
+	defaultTemplate = templateFull
+	defaultInitDir  = "."
+	initWriterType  = "file"
+)
+
+type scaffold struct {
+	APIVersion    string
+	UseSchema     bool
+	UseDefinition bool
+	UseProcedure  bool
+}
+
+var templateToScaffold = map[string]*scaffold{
+	templateFull: {
+		UseSchema:     true,
+		UseDefinition: true,
+		UseProcedure:  true,
+	},
+	templateJSONSchemaOnly: {
+		UseSchema: true,
+	},
+	templateProcedureOnly: {
+		UseProcedure: true,
+	},
+}
+
+func getInitCmd() *cobra.Command {
+	var (
+		templateName string
+		dir          string
+		force        bool
+	)
+	runCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize a recipe along with its skeleton files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathToContent, err := buildScaffold(templateName, dir)
+			if err != nil {
+				return err
+			}
+			if err := writeScaffold(pathToContent, force); err != nil {
+				return err
+			}
+			fmt.Printf("run the recipe with: %s\n", buildRunCommand(dir))
+			return nil
+		},
+	}
+	runCmd.Flags().StringVarP(&templateName, "template", "t", defaultTemplate,
+		fmt.Sprintf("Template to be used, one of: %s", strings.Join(getTemplateNames(), ", ")),
+	)
+	runCmd.Flags().StringVarP(&dir, "dir", "d", defaultInitDir, "Directory where the files are generated")
+	runCmd.Flags().BoolVar(&force, "force", false, "Overwrite the existing files")
+	return runCmd
+}
+
+func buildScaffold(templateName, dir string) (map[string]string, error) {
+	base := templateToScaffold[templateName]
+	if base == nil {
+		return nil, fmt.Errorf("template [%s] is not supported, available: %s",
+			templateName, strings.Join(getTemplateNames(), ", "),
+		)
+	}
+	scf := *base
+	scf.APIVersion = recipe.CurrentAPIVersion
+	dir = path.Clean(dir)
+
+	recipeContent, err := renderScaffold(scaffoldRecipe, &scf)
+	if err != nil {
+		return nil, err
+	}
+	output := map[string]string{
+		path.Join(dir, "valor.yaml"):              recipeContent,
+		path.Join(dir, "resource", "sample.json"): scaffoldResource,
+	}
+	if scf.UseSchema {
+		output[path.Join(dir, "schema", "sample_rule.json")] = scaffoldSchema
+	}
+	if scf.UseDefinition {
+		output[path.Join(dir, "definition", "sample.json")] = scaffoldDefinition
+		output[path.Join(dir, "procedure", "construct_sample_dictionary.jsonnet")] = scaffoldFunction
+	}
+	if scf.UseProcedure {
+		procedureContent := scaffoldProcedure
+		if scf.UseDefinition {
+			procedureContent = scaffoldProcedureWithDefinition
+		}
+		output[path.Join(dir, "procedure", "evaluate_sample.jsonnet")] = procedureContent
+	}
+	return output, nil
+}
+
+// buildRunCommand builds the command to execute the generated recipe from its own directory
+func buildRunCommand(dir string) string {
+	dir = path.Clean(dir)
+	if dir == defaultInitDir {
+		return "valor execute"
+	}
+	return fmt.Sprintf("cd %s && valor execute", dir)
+}
+
+func writeScaffold(pathToContent map[string]string, force bool) error {
+	var paths []string
+	for p := range pathToContent {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if !force {
+		var existingPaths []string
+		for _, p := range paths {
+			if _, err := os.Stat(p); err == nil {
+				existingPaths = append(existingPaths, p)
+			}
+		}
+		if len(existingPaths) > 0 {
+			return fmt.Errorf("file [%s] already exists, use --force to overwrite",
+				strings.Join(existingPaths, ", "),
+			)
+		}
+	}
+	writerFn, err := io.Writers.Get(initWriterType)
+	if err != nil {
+		return err
+	}
+	writer := writerFn(model.TreatmentInfo)
+	for _, p := range paths {
+		if err := writer.Write(&model.Data{
+			Type:    initWriterType,
+			Path:    p,
+			Content: []byte(pathToContent[p]),
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("created %s\n", p)
+	}
+	return nil
+}
+
+func renderScaffold(text string, scf *scaffold) (string, error) {
+	tmpl, err := template.New("scaffold").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buff := &bytes.Buffer{}
+	if err := tmpl.Execute(buff, scf); err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
+
+func getTemplateNames() []string {
+	var output []string
+	for name := range templateToScaffold {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+	return output
+}
+
+// scaffoldRecipe refers to the files relative to the directory of the recipe, since paths within
+// a recipe are resolved from where it is executed, which is meant to be the directory of the recipe
+const scaffoldRecipe = `apiVersion: {{ .APIVersion }}
+resources:
+- name: sample
+  type: dir
+  path: ./resource
+  format: json
+  framework_names:
+  - sample_evaluation
+
+frameworks:
+- name: sample_evaluation
+{{- if .UseSchema }}
+  schemas:
+  - name: sample_rule
+    type: file
+    path: ./schema/sample_rule.json
+    output:
+      treat_as: error
+      targets:
+      - name: std_output
+        type: std
+        format: yaml
+{{- end }}
+{{- if .UseDefinition }}
+  definitions:
+  - name: categories
+    format: json
+    type: dir
+    path: ./definition
+    function:
+      type: file
+      path: ./procedure/construct_sample_dictionary.jsonnet
+{{- end }}
+{{- if .UseProcedure }}
+  procedures:
+  - name: evaluate_sample
+    type: file
+    path: ./procedure/evaluate_sample.jsonnet
+    output:
+      treat_as: success
+      targets:
+      - name: std_output
+        type: std
+        format: yaml
+{{- end }}
+`
+
+const scaffoldResource = `{
+    "name": "sample",
+    "category_id": 1,
+    "is_active": true
+}
+`
+
+const scaffoldSchema = `{
+    "title": "sample",
+    "description": "Schema to validate sample resource.",
+    "type": "object",
+    "properties": {
+        "name": {
+            "type": "string"
+        },
+        "category_id": {
+            "type": "integer"
+        },
+        "is_active": {
+            "type": "boolean"
+        }
+    },
+    "required": [
+        "name",
+        "category_id"
+    ],
+    "additionalProperties": false
+}
+`
+
+const scaffoldDefinition = `{
+    "id": 1,
+    "name": "default",
+    "description": "Default category"
+}
+`
+
+const scaffoldFunction = `local construct(definition) = {
+    [std.toString(d.id)]: d
+    for d in definition
+};
+
+construct
+`
+
+const scaffoldProcedure = `local evaluate(resource, definition, previousOutput) = {
+    name: resource.name,
+    is_active: resource.is_active,
+};
+
+evaluate
+`
+
+const scaffoldProcedureWithDefinition = `local evaluate(resource, definition, previousOutput) =
+    local category_dict = definition['categories'];
+    local category = category_dict[std.toString(resource.category_id)];
+    {
+        name: resource.name,
+        category: category.name,
+        is_active: resource.is_active,
+    };
+
+evaluate
+`
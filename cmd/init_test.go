@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	_ "github.com/gojek/optimus-extension-valor/plugin/endec"
+	_ "github.com/gojek/optimus-extension-valor/plugin/explorer"
+	_ "github.com/gojek/optimus-extension-valor/plugin/formatter"
+	_ "github.com/gojek/optimus-extension-valor/plugin/io" // init file writer
+	_ "github.com/gojek/optimus-extension-valor/plugin/progress"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const initDirName = "./init"
+
+type InitSuite struct {
+	suite.Suite
+
+	workingDir string
+}
+
+func (i *InitSuite) SetupSuite() {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	i.workingDir = workingDir
+}
+
+func (i *InitSuite) SetupTest() {
+	if err := os.MkdirAll(initDirName, os.ModePerm); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(initDirName); err != nil {
+		panic(err)
+	}
+}
+
+func (i *InitSuite) TestBuildScaffold() {
+	i.Run("should return nil and error if template is not supported", func() {
+		actualPathToContent, actualErr := buildScaffold("unknown", defaultInitDir)
+
+		i.Nil(actualPathToContent)
+		i.NotNil(actualErr)
+	})
+
+	i.Run("should return every file of the template under the directory", func() {
+		expectedPaths := []string{
+			"sample/valor.yaml",
+			"sample/resource/sample.json",
+			"sample/schema/sample_rule.json",
+		}
+
+		actualPathToContent, actualErr := buildScaffold(templateJSONSchemaOnly, "sample")
+
+		i.Nil(actualErr)
+		var actualPaths []string
+		for p := range actualPathToContent {
+			actualPaths = append(actualPaths, p)
+		}
+		i.ElementsMatch(expectedPaths, actualPaths)
+		i.Contains(actualPathToContent["sample/valor.yaml"], "path: ./resource")
+	})
+
+	i.Run("should return bare paths with relative recipe paths if directory is the current one", func() {
+		actualPathToContent, actualErr := buildScaffold(templateFull, defaultInitDir)
+
+		i.Nil(actualErr)
+		i.Len(actualPathToContent, 6)
+		i.Contains(actualPathToContent, "valor.yaml")
+		i.Contains(actualPathToContent["valor.yaml"], "path: ./resource")
+		i.Contains(actualPathToContent["procedure/evaluate_sample.jsonnet"], "definition")
+	})
+}
+
+func (i *InitSuite) TestBuildRunCommand() {
+	i.Run("should change to the directory before executing the recipe", func() {
+		expectedCommand := "cd sample && valor execute"
+
+		actualCommand := buildRunCommand("./sample/")
+
+		i.Equal(expectedCommand, actualCommand)
+	})
+
+	i.Run("should only execute the recipe if directory is the current one", func() {
+		expectedCommand := "valor execute"
+
+		actualCommand := buildRunCommand(defaultInitDir)
+
+		i.Equal(expectedCommand, actualCommand)
+	})
+}
+
+func (i *InitSuite) TestExecuteScaffold() {
+	i.Run("should execute the generated recipe from its own directory", func() {
+		for _, templateName := range getTemplateNames() {
+			dir := path.Join("sample", templateName)
+			pathToContent, err := buildScaffold(templateName, dir)
+			i.Require().NoError(err)
+			i.Require().NoError(writeScaffold(pathToContent, false))
+			workingDir, err := os.Getwd()
+			i.Require().NoError(err)
+			i.Require().NoError(os.Chdir(dir))
+
+			actualErr := runPipeline(defaultRecipePath, "iterative", nil)
+
+			i.Require().NoError(os.Chdir(workingDir))
+			i.Nil(actualErr, templateName)
+		}
+	})
+}
+
+func (i *InitSuite) TestWriteScaffold() {
+	i.Run("should write every file including the ones without directory", func() {
+		pathToContent, _ := buildScaffold(templateFull, defaultInitDir)
+
+		actualErr := writeScaffold(pathToContent, false)
+
+		i.Nil(actualErr)
+		for p, expectedContent := range pathToContent {
+			actualContent, err := ioutil.ReadFile(p)
+			i.Require().NoError(err)
+			i.Equal(expectedContent, string(actualContent))
+		}
+	})
+
+	i.Run("should return error and keep the file if file exists and force is false", func() {
+		pathToContent := map[string]string{"valor.yaml": "new content"}
+		if err := ioutil.WriteFile("valor.yaml", []byte("old content"), os.ModePerm); err != nil {
+			panic(err)
+		}
+
+		actualErr := writeScaffold(pathToContent, false)
+
+		i.NotNil(actualErr)
+		actualContent, _ := ioutil.ReadFile("valor.yaml")
+		i.Equal("old content", string(actualContent))
+	})
+
+	i.Run("should overwrite the file if file exists and force is true", func() {
+		pathToContent := map[string]string{"valor.yaml": "new content"}
+		if err := ioutil.WriteFile("valor.yaml", []byte("old content"), os.ModePerm); err != nil {
+			panic(err)
+		}
+
+		actualErr := writeScaffold(pathToContent, true)
+
+		i.Nil(actualErr)
+		actualContent, _ := ioutil.ReadFile("valor.yaml")
+		i.Equal("new content", string(actualContent))
+	})
+}
+
+func (i *InitSuite) TearDownTest() {
+	if err := os.Chdir(i.workingDir); err != nil {
+		panic(err)
+	}
+	if err := os.RemoveAll(path.Join(i.workingDir, initDirName)); err != nil {
+		panic(err)
+	}
+}
+
+func TestInitSuite(t *testing.T) {
+	suite.Run(t, new(InitSuite))
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	lintOutputText  = "text"
+	lintOutputJSON  = "json"
+	lintOutputSARIF = "sarif"
+
+	lintRuleRecipe = "recipe"
+
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+func getLintCmd() *cobra.Command {
+	var output string
+	runCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint the recipe and its Jsonnet files without executing them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issues := lintRecipe(recipePath)
+			content, err := renderIssues(issues, output)
+			if err != nil {
+				return err
+			}
+			fmt.Println(content)
+			var numOfErrors int
+			for _, issue := range issues {
+				if issue.Severity == model.SeverityError {
+					numOfErrors++
+				}
+			}
+			if numOfErrors > 0 {
+				return fmt.Errorf("lint found %d error(s)", numOfErrors)
+			}
+			return nil
+		},
+	}
+	runCmd.PersistentFlags().StringVarP(&recipePath, "recipe-path", "R", defaultRecipePath, "Path of the recipe file")
+	runCmd.PersistentFlags().StringVar(&recipeFormat, "recipe-format", "", "Format of the recipe file, one of: yaml, json, jsonnet. Detected from the path extension if not specified")
+	runCmd.PersistentFlags().StringArrayVar(&setValues, "set", nil, "Parameter to be interpolated in the recipe, in the form of key=value")
+	runCmd.PersistentFlags().StringVarP(&output, "output", "o", lintOutputText, "Output format, one of: text, json, sarif")
+	return runCmd
+}
+
+func lintRecipe(recipePath string) []*model.Issue {
+	newIssue := func(message string) *model.Issue {
+		return &model.Issue{
+			Rule:     lintRuleRecipe,
+			Severity: model.SeverityError,
+			Path:     recipePath,
+			Message:  message,
+		}
+	}
+	params, err := parseSetValues(setValues)
+	if err != nil {
+		return []*model.Issue{newIssue(err.Error())}
+	}
+	rcp, err := loadRecipe(recipePath, defaultRecipeType, recipeFormat, params)
+	if err != nil {
+		return []*model.Issue{newIssue(err.Error())}
+	}
+
+	var output []*model.Issue
+	if err := recipe.ValidateDeep(rcp); err != nil {
+		if e, ok := err.(*model.Error); ok {
+			keyToValue := e.Map()
+			var keys []string
+			for key := range keyToValue {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				output = append(output, newIssue(fmt.Sprintf("%s: %v", key, keyToValue[key])))
+			}
+		} else {
+			output = append(output, newIssue(err.Error()))
+		}
+	}
+	return append(output, core.NewLinter().LintFrameworks(rcp.Frameworks)...)
+}
+
+func renderIssues(issues []*model.Issue, output string) (string, error) {
+	switch strings.ToLower(output) {
+	case lintOutputText:
+		return renderIssuesAsText(issues), nil
+	case lintOutputJSON:
+		if issues == nil {
+			issues = []*model.Issue{}
+		}
+		content, err := json.MarshalIndent(issues, "", "  ")
+		return string(content), err
+	case lintOutputSARIF:
+		content, err := json.MarshalIndent(buildSARIF(issues), "", "  ")
+		return string(content), err
+	default:
+		return "", fmt.Errorf("output [%s] is not supported", output)
+	}
+}
+
+func renderIssuesAsText(issues []*model.Issue) string {
+	if len(issues) == 0 {
+		return "no issue found"
+	}
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		location := issue.Path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", location, issue.Line, issue.Column)
+		}
+		lines[i] = fmt.Sprintf("%s: %s: %s [%s]", location, issue.Severity, issue.Message, issue.Rule)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func buildSARIF(issues []*model.Issue) map[string]interface{} {
+	var ruleIDs []string
+	ruleEncountered := make(map[string]bool)
+	results := make([]map[string]interface{}, len(issues))
+	for i, issue := range issues {
+		if !ruleEncountered[issue.Rule] {
+			ruleEncountered[issue.Rule] = true
+			ruleIDs = append(ruleIDs, issue.Rule)
+		}
+		physicalLocation := map[string]interface{}{
+			"artifactLocation": map[string]interface{}{
+				"uri": issue.Path,
+			},
+		}
+		if issue.Line > 0 {
+			physicalLocation["region"] = map[string]interface{}{
+				"startLine":   issue.Line,
+				"startColumn": issue.Column,
+			}
+		}
+		results[i] = map[string]interface{}{
+			"ruleId": issue.Rule,
+			"level":  string(issue.Severity),
+			"message": map[string]interface{}{
+				"text": issue.Message,
+			},
+			"locations": []map[string]interface{}{
+				{"physicalLocation": physicalLocation},
+			},
+		}
+	}
+	rules := make([]map[string]interface{}, len(ruleIDs))
+	for i, id := range ruleIDs {
+		rules[i] = map[string]interface{}{"id": id}
+	}
+	return map[string]interface{}{
+		"version": sarifVersion,
+		"$schema": sarifSchema,
+		"runs": []map[string]interface{}{
+			{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":  "valor",
+						"rules": rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+}
@@ -1,75 +1,184 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
 	"github.com/gojek/optimus-extension-valor/recipe"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	profileOutputTable = "table"
+	profileOutputJSON  = "json"
+	profileOutputYAML  = "yaml"
 )
 
 func getProfileCmd() *cobra.Command {
+	var output string
 	runCmd := &cobra.Command{
 		Use:   "profile",
 		Short: "Profile the recipe specified by path",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			rcp, err := loadRecipe(recipePath, defaultRecipeType, defaultRecipeFormat)
+			params, err := parseSetValues(setValues)
 			if err != nil {
 				return err
 			}
+			rcp, err := loadRecipe(recipePath, defaultRecipeType, recipeFormat, params)
+			if err != nil {
+				return err
+			}
+			if err := enrichWithTags(rcp); err != nil {
+				return err
+			}
+			if err := recipe.ResolveExtends(rcp); err != nil {
+				return err
+			}
+			if err := recipe.ResolveOutputRefs(rcp); err != nil {
+				return err
+			}
 
-			fmt.Println("RESOURCE:")
-			resourceTable := getResourceTable(rcp)
-			resourceTable.Render()
-			fmt.Println()
-
-			fmt.Println("FRAMEWORK:")
-			frameworkTable := getFrameworkTable(rcp)
-			frameworkTable.Render()
-			return nil
+			profile, err := core.Profile(rcp)
+			if err != nil {
+				if e, ok := err.(*model.Error); ok {
+					return errors.New(string(e.JSON()))
+				}
+				return err
+			}
+			// only frameworks used by the selected resources are shown when tags are specified
+			if len(tags) > 0 || len(skipTags) > 0 {
+				profile.Frameworks = getSelectedFrameworks(profile)
+			}
+			return renderProfile(profile, output)
 		},
 	}
 	runCmd.PersistentFlags().StringVarP(&recipePath, "recipe-path", "R", defaultRecipePath, "Path of the recipe file")
+	runCmd.PersistentFlags().StringVar(&recipeFormat, "recipe-format", "", "Format of the recipe file, one of: yaml, json, jsonnet. Detected from the path extension if not specified")
+	runCmd.PersistentFlags().StringArrayVar(&setValues, "set", nil, "Parameter to be interpolated in the recipe, in the form of key=value")
+	runCmd.PersistentFlags().StringSliceVar(&tags, "tags", nil, "Only profile resources and frameworks having at least one of the tags")
+	runCmd.PersistentFlags().StringSliceVar(&skipTags, "skip-tags", nil, "Skip resources and frameworks having any of the tags")
+	runCmd.PersistentFlags().StringVarP(&output, "output", "o", profileOutputTable, "Output format, one of: table, json, yaml")
 	return runCmd
 }
 
-func getResourceTable(rcp *recipe.Recipe) *tablewriter.Table {
+func getSelectedFrameworks(profile *model.Profile) []*model.FrameworkProfile {
+	selected := make(map[string]bool)
+	for _, r := range profile.Resources {
+		for _, frameworkName := range r.FrameworkNames {
+			selected[frameworkName] = true
+		}
+	}
+	var output []*model.FrameworkProfile
+	for _, f := range profile.Frameworks {
+		if selected[f.Name] {
+			output = append(output, f)
+		}
+	}
+	return output
+}
+
+func renderProfile(profile *model.Profile, output string) error {
+	switch strings.ToLower(output) {
+	case profileOutputTable:
+		fmt.Println("RESOURCE:")
+		getResourceTable(profile).Render()
+		fmt.Println()
+
+		fmt.Println("FRAMEWORK:")
+		getFrameworkTable(profile).Render()
+
+		if len(profile.Defaulted) > 0 {
+			fmt.Println()
+			fmt.Println("DEFAULTED:")
+			getDefaultedTable(profile).Render()
+		}
+		return nil
+	case profileOutputJSON:
+		content, err := json.MarshalIndent(profile, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+		return nil
+	case profileOutputYAML:
+		content, err := yaml.Marshal(profile)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(content))
+		return nil
+	default:
+		return fmt.Errorf("output [%s] is not supported", output)
+	}
+}
+
+func getResourceTable(profile *model.Profile) *tablewriter.Table {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Format", "Type", "Path", "Batch Size", "Framework"})
+	table.SetHeader([]string{"Name", "Format", "Type", "Path", "Files", "Batch Size", "Tags", "Framework"})
 	table.SetAutoMergeCells(true)
 	table.SetRowLine(true)
-	for _, r := range rcp.Resources {
+	for _, r := range profile.Resources {
 		for _, frameworkName := range r.FrameworkNames {
-			table.Append([]string{r.Name, r.Format, r.Type, r.Path, fmt.Sprintf("%d", r.BatchSize), frameworkName})
+			table.Append([]string{r.Name, r.Format, r.Type, r.Path, fmt.Sprintf("%d", r.FileCount), fmt.Sprintf("%d", r.BatchSize), strings.Join(r.Tags, ", "), frameworkName})
 		}
 	}
 	return table
 }
 
-func getFrameworkTable(rcp *recipe.Recipe) *tablewriter.Table {
+func getFrameworkTable(profile *model.Profile) *tablewriter.Table {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Framework", "Type", "Name"})
+	table.SetHeader([]string{"Framework", "Type", "Name", "Detail"})
 	table.SetAutoMergeCells(true)
 	table.SetRowLine(true)
-	for _, f := range rcp.Frameworks {
+	for _, f := range profile.Frameworks {
+		if len(f.Tags) > 0 {
+			table.Append([]string{f.Name, "tags", strings.Join(f.Tags, ", "), ""})
+		}
 		for _, d := range f.Definitions {
-			table.Append([]string{f.Name, "definition", d.Name})
+			table.Append([]string{f.Name, "definition", d.Name, fmt.Sprintf("%d file(s)", len(d.Files))})
 		}
 		for _, s := range f.Schemas {
-			table.Append([]string{f.Name, "schema", s.Name})
+			table.Append([]string{f.Name, "schema", fmt.Sprintf("%d. %s", s.Order, s.Name), getStepDetail(s)})
 		}
 		for _, p := range f.Procedures {
-			table.Append([]string{f.Name, "procedure", p.Name})
+			table.Append([]string{f.Name, "procedure", fmt.Sprintf("%d. %s", p.Order, p.Name), getStepDetail(p)})
 		}
-		for _, p := range f.Procedures {
-			if p.Output != nil {
-				for _, t := range p.Output.Targets {
-					table.Append([]string{f.Name, "output", t.Path})
+		for _, steps := range [][]*model.StepProfile{f.Schemas, f.Procedures} {
+			for _, s := range steps {
+				for _, t := range s.Targets {
+					detail := fmt.Sprintf("%s: %s, %s", s.Name, t.Type, t.Format)
+					if t.Path != "" {
+						detail += ", " + t.Path
+					}
+					table.Append([]string{f.Name, "output", t.Name, detail})
 				}
 			}
 		}
 	}
 	return table
 }
+
+func getStepDetail(step *model.StepProfile) string {
+	if step.When == "" {
+		return step.Path
+	}
+	return fmt.Sprintf("%s (when: %s)", step.Path, step.When)
+}
+
+func getDefaultedTable(profile *model.Profile) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Field", "Value"})
+	table.SetRowLine(true)
+	for _, d := range profile.Defaulted {
+		table.Append([]string{d.Key, d.Value})
+	}
+	return table
+}
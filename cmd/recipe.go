@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+	"github.com/gojek/optimus-extension-valor/registry/io"
+
+	"github.com/spf13/cobra"
+)
+
+func getRecipeCmd() *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:   "recipe",
+		Short: "Utilities related to the recipe format",
+	}
+	runCmd.AddCommand(getRecipeSchemaCmd())
+	runCmd.AddCommand(getRecipeMigrateCmd())
+	return runCmd
+}
+
+func getRecipeSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the recipe format",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := json.MarshalIndent(recipe.GenerateJSONSchema(), "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(content))
+			return nil
+		},
+	}
+}
+
+func getRecipeMigrateCmd() *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the recipe file to the latest version, while keeping its formatting",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrateRecipe(recipePath)
+		},
+	}
+	runCmd.PersistentFlags().StringVarP(&recipePath, "recipe-path", "R", defaultRecipePath, "Path of the recipe file")
+	runCmd.PersistentFlags().StringVar(&recipeFormat, "recipe-format", "", "Format of the recipe file. Detected from the path extension if not specified")
+	return runCmd
+}
+
+func migrateRecipe(path string) error {
+	_type, path := parseRecipePath(path, defaultRecipeType)
+	if format := getRecipeFormat(path, recipeFormat); format != defaultRecipeFormat {
+		return fmt.Errorf("recipe with format [%s] cannot be migrated, only [%s] is supported", format, defaultRecipeFormat)
+	}
+	readerFn, err := io.Readers.Get(_type)
+	if err != nil {
+		return err
+	}
+	getPath := func() string {
+		return path
+	}
+	postProcess := func(p string, c []byte) (*model.Data, error) {
+		return &model.Data{
+			Content: c,
+			Path:    p,
+			Type:    defaultRecipeFormat,
+		}, nil
+	}
+	data, err := readerFn(getPath, postProcess).Read()
+	if err != nil {
+		return err
+	}
+	content, versions, err := recipe.MigrateContent(data.Content)
+	if err != nil {
+		return err
+	}
+	// recipe from stdin cannot be written back, so the migrated recipe is printed instead
+	if _type == stdinRecipeType {
+		fmt.Print(string(content))
+		return nil
+	}
+	if len(versions) == 0 {
+		fmt.Printf("recipe [%s] is already at [%s]\n", path, recipe.CurrentAPIVersion)
+		return nil
+	}
+	writerFn, err := io.Writers.Get(_type)
+	if err != nil {
+		return err
+	}
+	if err := writerFn(model.TreatmentInfo).Write(&model.Data{
+		Content: content,
+		Path:    path,
+		Type:    defaultRecipeFormat,
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("recipe [%s] is migrated through [%s]\n", path, strings.Join(versions, " -> "))
+	return nil
+}
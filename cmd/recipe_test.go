@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const migrateDirName = "./migrate"
+
+func TestMigrateRecipe(t *testing.T) {
+	t.Run("should write the migrated recipe if path has no directory", func(t *testing.T) {
+		workingDir, _ := os.Getwd()
+		if err := os.MkdirAll(migrateDirName, os.ModePerm); err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(migrateDirName)
+		if err := os.Chdir(migrateDirName); err != nil {
+			panic(err)
+		}
+		defer os.Chdir(workingDir)
+		if err := ioutil.WriteFile("old.yaml", []byte("resources: []\n"), os.ModePerm); err != nil {
+			panic(err)
+		}
+
+		expectedContent := "apiVersion: v1\nresources: []\n"
+
+		actualErr := migrateRecipe("old.yaml")
+
+		assert.Nil(t, actualErr)
+		actualContent, _ := ioutil.ReadFile("old.yaml")
+		assert.Equal(t, expectedContent, string(actualContent))
+	})
+}
@@ -30,12 +30,15 @@ func getResourceCmd() *cobra.Command {
 				if err := enrichWithBatchSize(rcp); err != nil {
 					return err
 				}
-				return enrichWithArg(rcp, &resourceArg{
+				if err := enrichWithArg(rcp, &resourceArg{
 					Name:   name,
 					Format: format,
 					Type:   _type,
 					Path:   path,
-				})
+				}); err != nil {
+					return err
+				}
+				return enrichWithTags(rcp)
 			}
 			return executePipeline(recipePath, progressType, enrich)
 		},
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/gojek/optimus-extension-valor/recipe"
 	"github.com/spf13/cobra"
@@ -10,12 +12,30 @@ import (
 const (
 	defaultRecipeType   = "file"
 	defaultRecipeFormat = "yaml"
+	jsonRecipeFormat    = "json"
+	jsonnetRecipeFormat = "jsonnet"
+	stdinRecipeType     = "stdin"
+	stdinRecipePath     = "-"
+	recipeSchemeSuffix  = "://"
 	defaultRecipePath   = "./valor.yaml"
 
 	defaultBatchSize = 4
 )
 
-var recipePath string
+var (
+	recipePath   string
+	recipeFormat string
+	setValues    []string
+	tags         []string
+	skipTags     []string
+)
+
+var extensionToRecipeFormat = map[string]string{
+	".yaml":    defaultRecipeFormat,
+	".yml":     defaultRecipeFormat,
+	".json":    jsonRecipeFormat,
+	".jsonnet": jsonnetRecipeFormat,
+}
 
 // Execute executes command
 func Execute() {
@@ -25,6 +45,10 @@ func Execute() {
 	}
 	rootCmd.AddCommand(getExecuteCmd())
 	rootCmd.AddCommand(getProfileCmd())
+	rootCmd.AddCommand(getGraphCmd())
+	rootCmd.AddCommand(getLintCmd())
+	rootCmd.AddCommand(getInitCmd())
+	rootCmd.AddCommand(getRecipeCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -39,3 +63,47 @@ func enrichWithBatchSize(r *recipe.Recipe) error {
 	}
 	return nil
 }
+
+func enrichWithTags(r *recipe.Recipe) error {
+	if len(tags) == 0 && len(skipTags) == 0 {
+		return nil
+	}
+	recipe.SelectByTags(r, tags, skipTags)
+	if len(r.Resources) == 0 {
+		return fmt.Errorf("no resource is selected by tags [%s] and skip tags [%s]",
+			strings.Join(tags, ", "), strings.Join(skipTags, ", "),
+		)
+	}
+	return nil
+}
+
+func parseSetValues(values []string) (map[string]string, error) {
+	output := make(map[string]string)
+	for _, v := range values {
+		pair := strings.SplitN(v, "=", 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return nil, fmt.Errorf("set value [%s] should follow key=value", v)
+		}
+		output[pair[0]] = pair[1]
+	}
+	return output, nil
+}
+
+// parseRecipePath parses the recipe path into the reader type and the path to be read.
+// Path "-" is read from stdin, path with scheme, like "file://valor.yaml", is read by
+// the reader registered with the scheme as its type, otherwise the default type is used.
+func parseRecipePath(path, defaultType string) (string, string) {
+	if path == stdinRecipePath {
+		return stdinRecipeType, stdinRecipePath
+	}
+	idx := strings.Index(path, recipeSchemeSuffix)
+	if idx <= 0 {
+		return defaultType, path
+	}
+	_type := strings.ToLower(path[:idx])
+	output := path[idx+len(recipeSchemeSuffix):]
+	if _type == stdinRecipeType {
+		output = stdinRecipePath
+	}
+	return _type, output
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gojek/optimus-extension-valor/model"
+)
+
+const (
+	conditionTrue  = "true"
+	conditionFalse = "false"
+
+	schemaStepType    = "schema"
+	procedureStepType = "procedure"
+)
+
+var (
+	schemaConditionVariables    = []string{resourceArgument}
+	procedureConditionVariables = procedureArguments
+)
+
+// buildConditionSnippet builds the snippet of the when condition of a step, where the variables
+// are bound as arguments along with the valor object. An empty condition results in an empty snippet.
+func buildConditionSnippet(when string) string {
+	if strings.TrimSpace(when) == "" {
+		return ""
+	}
+	return fmt.Sprintf("%slocal %s = import \"%s\"; %s", buildArgumentsHeader(), nativeObjectName, nativeLibraryPath, when)
+}
+
+// evaluateCondition evaluates the snippet of the when condition of a step.
+// An empty snippet means the step always applies.
+func evaluateCondition(evaluate model.Evaluate, name, snippet string, arguments map[string]string) (bool, error) {
+	if snippet == "" {
+		return true, nil
+	}
+	result, err := evaluate(name+".when", snippet, arguments)
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(result) {
+	case conditionTrue:
+		return true, nil
+	case conditionFalse:
+		return false, nil
+	default:
+		return false, fmt.Errorf("when of [%s] should result in a boolean, but got [%s]", name, strings.TrimSpace(result))
+	}
+}
+
+// skipRecorder records resource paths skipped by every step, in the order the steps are first skipped
+type skipRecorder struct {
+	mtx         *sync.Mutex
+	keys        []string
+	keyToOutput map[string]*model.SkippedStep
+}
+
+func newSkipRecorder() *skipRecorder {
+	return &skipRecorder{
+		mtx:         &sync.Mutex{},
+		keyToOutput: make(map[string]*model.SkippedStep),
+	}
+}
+
+func (s *skipRecorder) record(_type, name, path string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	key := _type + "/" + name
+	if s.keyToOutput[key] == nil {
+		s.keys = append(s.keys, key)
+		s.keyToOutput[key] = &model.SkippedStep{
+			Type: _type,
+			Name: name,
+		}
+	}
+	s.keyToOutput[key].Paths = append(s.keyToOutput[key].Paths, path)
+}
+
+func (s *skipRecorder) list() []*model.SkippedStep {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	output := make([]*model.SkippedStep, len(s.keys))
+	for i, key := range s.keys {
+		step := *s.keyToOutput[key]
+		step.Paths = append([]string{}, step.Paths...)
+		output[i] = &step
+	}
+	return output
+}
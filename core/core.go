@@ -39,6 +39,7 @@ type Pipeline struct {
 	recipe      *recipe.Recipe
 	loader      *Loader
 	evaluate    model.Evaluate
+	addFile     model.AddFile
 	newProgress model.NewProgress
 
 	nameToFrameworkRecipe map[string]*recipe.Framework
@@ -48,6 +49,7 @@ type Pipeline struct {
 func NewPipeline(
 	rcp *recipe.Recipe,
 	evaluate model.Evaluate,
+	addFile model.AddFile,
 	newProgress model.NewProgress,
 ) (*Pipeline, error) {
 	if rcp == nil {
@@ -56,6 +58,9 @@ func NewPipeline(
 	if evaluate == nil {
 		return nil, errors.New("evaluate function is nil")
 	}
+	if addFile == nil {
+		return nil, errors.New("add file function is nil")
+	}
 	if newProgress == nil {
 		return nil, errors.New("new progress function is nil")
 	}
@@ -67,6 +72,7 @@ func NewPipeline(
 		recipe:                rcp,
 		loader:                &Loader{},
 		evaluate:              evaluate,
+		addFile:               addFile,
 		newProgress:           newProgress,
 		nameToFrameworkRecipe: nameToFrameworkRecipe,
 	}, nil
@@ -108,7 +114,7 @@ func (p *Pipeline) executeOnResource(resourceRcp *recipe.Resource, nameToValidat
 	if resourceRcp == nil {
 		return errors.New("resource recipe is nil")
 	}
-	resourcePaths, err := ExplorePaths(resourceRcp.Path, resourceRcp.Type, resourceRcp.Format, resourceRcp.RegexPattern)
+	resourcePaths, err := ExplorePathsWithOption(resourceRcp.Path, resourceRcp.Type, resourceRcp.Format, resourceRcp.RegexPattern, resourceRcp.Explore)
 	if err != nil {
 		return err
 	}
@@ -197,6 +203,7 @@ func (p *Pipeline) executeOnResource(resourceRcp *recipe.Resource, nameToValidat
 		counter += batch
 	}
 	progress.Wait()
+	printSkipped(resourceRcp.FrameworkNames, nameToValidator, nameToEvaluator)
 
 	if outputError.Length() > 0 {
 		return outputError
@@ -204,11 +211,33 @@ func (p *Pipeline) executeOnResource(resourceRcp *recipe.Resource, nameToValidat
 	return nil
 }
 
+func printSkipped(frameworkNames []string, nameToValidator map[string]*Validator, nameToEvaluator map[string]*Evaluator) {
+	var lines []string
+	for _, frameworkName := range frameworkNames {
+		var skipped []*model.SkippedStep
+		if validator := nameToValidator[frameworkName]; validator != nil {
+			skipped = append(skipped, validator.Skipped()...)
+		}
+		if evaluator := nameToEvaluator[frameworkName]; evaluator != nil {
+			skipped = append(skipped, evaluator.Skipped()...)
+		}
+		for _, s := range skipped {
+			lines = append(lines, fmt.Sprintf("   framework [%s] %s [%s]: %d file(s)",
+				frameworkName, s.Type, s.Name, len(s.Paths),
+			))
+		}
+	}
+	if len(lines) > 0 {
+		fmt.Println("o> skipped by when condition")
+		fmt.Println(strings.Join(lines, "\n"))
+	}
+}
+
 func (p *Pipeline) getFrameworkNameToValidator(nameToFramework map[string]*model.Framework) (map[string]*Validator, error) {
 	outputValidator := make(map[string]*Validator)
 	outputError := &model.Error{}
 	for name, framework := range nameToFramework {
-		validator, err := NewValidator(framework)
+		validator, err := NewValidator(framework, p.evaluate)
 		if err != nil {
 			outputError.Add(name, err)
 		} else {
@@ -232,7 +261,7 @@ func (p *Pipeline) getFrameworkNameToEvaluator(nameToFramework map[string]*model
 
 		go func(n string, f *model.Framework, w *sync.WaitGroup, m *sync.Mutex) {
 			defer w.Done()
-			evaluator, err := NewEvaluator(f, p.evaluate)
+			evaluator, err := NewEvaluator(f, p.evaluate, p.addFile)
 			if err != nil {
 				outputError.Add(n, err)
 			} else {
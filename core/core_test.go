@@ -13,14 +13,14 @@ import (
 func TestNewPipeline(t *testing.T) {
 	t.Run("should return nil and error if recipe is nil", func(t *testing.T) {
 		var rcp *recipe.Recipe = nil
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 		var newProgress model.NewProgress = func(name string, total int) model.Progress {
 			return nil
 		}
 
-		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, newProgress)
+		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, ignoreFile, newProgress)
 
 		assert.Nil(t, actualPipeline)
 		assert.NotNil(t, actualErr)
@@ -33,7 +33,23 @@ func TestNewPipeline(t *testing.T) {
 			return nil
 		}
 
-		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, newProgress)
+		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, ignoreFile, newProgress)
+
+		assert.Nil(t, actualPipeline)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if add file is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = &recipe.Recipe{}
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			return "", nil
+		}
+		var addFile model.AddFile = nil
+		var newProgress model.NewProgress = func(name string, total int) model.Progress {
+			return nil
+		}
+
+		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, addFile, newProgress)
 
 		assert.Nil(t, actualPipeline)
 		assert.NotNil(t, actualErr)
@@ -41,12 +57,12 @@ func TestNewPipeline(t *testing.T) {
 
 	t.Run("should return nil and error if newProgress is nil", func(t *testing.T) {
 		var rcp *recipe.Recipe = &recipe.Recipe{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 		var newProgress model.NewProgress = nil
 
-		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, newProgress)
+		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, ignoreFile, newProgress)
 
 		assert.Nil(t, actualPipeline)
 		assert.NotNil(t, actualErr)
@@ -54,14 +70,14 @@ func TestNewPipeline(t *testing.T) {
 
 	t.Run("should return pipeline and nil if no error is encountered", func(t *testing.T) {
 		var rcp *recipe.Recipe = &recipe.Recipe{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 		var newProgress model.NewProgress = func(name string, total int) model.Progress {
 			return nil
 		}
 
-		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, newProgress)
+		actualPipeline, actualErr := core.NewPipeline(rcp, evaluate, ignoreFile, newProgress)
 
 		assert.NotNil(t, actualPipeline)
 		assert.Nil(t, actualErr)
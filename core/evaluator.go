@@ -1,30 +1,68 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/gojek/optimus-extension-valor/model"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+const (
+	resourceArgument       = "resource"
+	definitionArgument     = "definition"
+	previousOutputArgument = "previousOutput"
+	outputsArgument        = "outputs"
 )
 
+// procedureArguments are accepted by every snippet, since a snippet evaluated
+// on a VM receives every argument bound by the earlier evaluations on it
+var procedureArguments = []string{resourceArgument, definitionArgument, previousOutputArgument, outputsArgument}
+
 // Evaluator contains information on how to evaluate a Resource
 type Evaluator struct {
 	evaluate          model.Evaluate
 	framework         *model.Framework
 	definitionSnippet string
+	procedureSnippets []*procedureSnippet
+	skipped           *skipRecorder
+}
+
+// procedureSnippet is the snippet of a procedure along with its when condition,
+// which are built once for the framework and reused for every resource
+type procedureSnippet struct {
+	function  string
+	condition string
 }
 
-// NewEvaluator initializes Evaluator
-func NewEvaluator(framework *model.Framework, evaluate model.Evaluate) (*Evaluator, error) {
+// NewEvaluator initializes Evaluator, where every procedure and definition function
+// is added as a file through addFile, so it is imported by the snippet calling it
+func NewEvaluator(framework *model.Framework, evaluate model.Evaluate, addFile model.AddFile) (*Evaluator, error) {
 	if framework == nil {
 		return nil, errors.New("framework is nil")
 	}
 	if evaluate == nil {
 		return nil, errors.New("evaluate function is nil")
 	}
-	definitionSnippet, err := buildAllDefinitions(evaluate, framework.Definitions)
+	if addFile == nil {
+		return nil, errors.New("add file function is nil")
+	}
+	if err := validateProcedureInputs(framework.Procedures); err != nil {
+		return nil, err
+	}
+	definitionSnippet, err := buildAllDefinitions(evaluate, addFile, framework.Definitions)
+	if err != nil {
+		return nil, err
+	}
+	procedureSnippets, err := buildAllProcedureSnippets(addFile, framework.Procedures)
 	if err != nil {
 		return nil, err
 	}
@@ -32,9 +70,16 @@ func NewEvaluator(framework *model.Framework, evaluate model.Evaluate) (*Evaluat
 		evaluate:          evaluate,
 		framework:         framework,
 		definitionSnippet: definitionSnippet,
+		procedureSnippets: procedureSnippets,
+		skipped:           newSkipRecorder(),
 	}, nil
 }
 
+// Skipped returns the procedures skipped by their when condition along with the skipped resource paths
+func (e *Evaluator) Skipped() []*model.SkippedStep {
+	return e.skipped.list()
+}
+
 // Evaluate evaluates snippet for a Resource data
 func (e *Evaluator) Evaluate(resourceData *model.Data) (bool, error) {
 	if resourceData == nil {
@@ -42,20 +87,38 @@ func (e *Evaluator) Evaluate(resourceData *model.Data) (bool, error) {
 	}
 	resourceSnippet := string(resourceData.Content)
 	previousOutputSnippet := model.SkipNullValue
+	nameToOutputSnippet := make(map[string]string)
 	for i, procedure := range e.framework.Procedures {
 		if procedure == nil {
 			return false, fmt.Errorf("procedure [%d] is nil", i)
 		}
-		snippet, err := buildSnippet(resourceSnippet, e.definitionSnippet, previousOutputSnippet, procedure)
+		if procedure.Data == nil {
+			return false, fmt.Errorf("procedure data for [%s] is nil", procedure.Name)
+		}
+		arguments := map[string]string{
+			resourceArgument:       resourceSnippet,
+			definitionArgument:     e.definitionSnippet,
+			previousOutputArgument: previousOutputSnippet,
+			outputsArgument:        buildOutputsSnippet(procedure.Inputs, nameToOutputSnippet),
+		}
+		snippet := e.procedureSnippets[i]
+		applied, err := evaluateCondition(e.evaluate, procedure.Name, snippet.condition, arguments)
 		if err != nil {
 			return false, err
 		}
-		result, evalErr := e.evaluate(procedure.Name, snippet)
+		if !applied {
+			// a skipped procedure does not change the previous output
+			e.skipped.record(procedureStepType, procedure.Name, resourceData.Path)
+			nameToOutputSnippet[procedure.Name] = model.SkipNullValue
+			continue
+		}
+		result, evalErr := e.evaluate(procedure.Data.Path, snippet.function, arguments)
 		if evalErr != nil {
 			return false, evalErr
 		}
 		if model.IsSkipResult[result] {
 			previousOutputSnippet = model.SkipNullValue
+			nameToOutputSnippet[procedure.Name] = model.SkipNullValue
 		} else {
 			success, err := treatOutput(
 				&model.Data{
@@ -72,12 +135,79 @@ func (e *Evaluator) Evaluate(resourceData *model.Data) (bool, error) {
 				return false, nil
 			}
 			previousOutputSnippet = result
+			nameToOutputSnippet[procedure.Name] = result
 		}
 	}
 	return true, nil
 }
 
-func buildAllDefinitions(evaluate model.Evaluate, definitions []*model.Definition) (string, error) {
+// validateProcedureInputs validates that every input of a procedure
+// refers to a procedure executed before it within the same framework
+func validateProcedureInputs(procedures []*model.Procedure) error {
+	outputError := &model.Error{}
+	earlierNames := make(map[string]bool)
+	for _, procedure := range procedures {
+		if procedure == nil {
+			continue
+		}
+		for _, input := range procedure.Inputs {
+			if !earlierNames[input] {
+				key := fmt.Sprintf("%s.inputs[%s]", procedure.Name, input)
+				outputError.Add(key, fmt.Errorf("input [%s] does not refer to an earlier procedure", input))
+			}
+		}
+		earlierNames[procedure.Name] = true
+	}
+	if outputError.Length() > 0 {
+		return outputError
+	}
+	return nil
+}
+
+// buildAllProcedureSnippets adds every procedure as a file, then builds the snippets calling it.
+// The outputs are only passed to a procedure with inputs, as the fourth argument of its function.
+func buildAllProcedureSnippets(addFile model.AddFile, procedures []*model.Procedure) ([]*procedureSnippet, error) {
+	outputError := &model.Error{}
+	output := make([]*procedureSnippet, len(procedures))
+	for i, procedure := range procedures {
+		output[i] = &procedureSnippet{}
+		if procedure == nil || procedure.Data == nil {
+			continue
+		}
+		if err := addFile(procedure.Data.Path, buildFunctionFile(procedure.Data, evaluateFunctionName)); err != nil {
+			outputError.Add(procedure.Name, err)
+			continue
+		}
+		argumentNames := []string{resourceArgument, definitionArgument, previousOutputArgument}
+		if len(procedure.Inputs) > 0 {
+			argumentNames = append(argumentNames, outputsArgument)
+		}
+		output[i].function = buildCallSnippet(procedure.Data.Path, argumentNames)
+		output[i].condition = buildConditionSnippet(procedure.When)
+	}
+	if outputError.Length() > 0 {
+		return nil, outputError
+	}
+	return output, nil
+}
+
+func buildOutputsSnippet(inputs []string, nameToOutputSnippet map[string]string) string {
+	var outputSnippets []string
+	for _, input := range inputs {
+		outputSnippet, ok := nameToOutputSnippet[input]
+		if !ok {
+			outputSnippet = model.SkipNullValue
+		}
+		key, _ := json.Marshal(input)
+		outputSnippets = append(outputSnippets, fmt.Sprintf(`%s: %s,`, key, outputSnippet))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(outputSnippets, "\n"))
+}
+
+// buildAllDefinitions evaluates every definition once, then adds the result as a file named after its digest,
+// so the same definition is always found at the same path. It returns the snippet importing the file, which is
+// passed as an argument, so every VM parses and evaluates the definition once instead of for every resource.
+func buildAllDefinitions(evaluate model.Evaluate, addFile model.AddFile, definitions []*model.Definition) (string, error) {
 	wg := &sync.WaitGroup{}
 	mtx := &sync.Mutex{}
 
@@ -88,7 +218,7 @@ func buildAllDefinitions(evaluate model.Evaluate, definitions []*model.Definitio
 
 		go func(idx int, w *sync.WaitGroup, m *sync.Mutex, d *model.Definition) {
 			defer w.Done()
-			defSnippet, err := buildOneDefinition(evaluate, d)
+			defSnippet, err := buildOneDefinition(evaluate, addFile, d)
 			if err != nil {
 				key := fmt.Sprintf("%d", idx)
 				if d != nil {
@@ -107,85 +237,45 @@ func buildAllDefinitions(evaluate model.Evaluate, definitions []*model.Definitio
 	if outputError.Length() > 0 {
 		return model.SkipNullValue, outputError
 	}
+	if len(nameToSnippet) == 0 {
+		return "{}", nil
+	}
 	var outputSnippets []string
-	for key, value := range nameToSnippet {
-		outputSnippets = append(outputSnippets, fmt.Sprintf(`"%s": %s,`, key, value))
+	for name, value := range nameToSnippet {
+		key, _ := json.Marshal(name)
+		outputSnippets = append(outputSnippets, fmt.Sprintf(`%s: %s,`, key, value))
+	}
+	result, err := evaluate(definitionArgument, fmt.Sprintf("{%s}", strings.Join(outputSnippets, "\n")), nil)
+	if err != nil {
+		return model.SkipNullValue, err
+	}
+	path := fmt.Sprintf("%s-%x.json", definitionArgument, sha256.Sum256([]byte(result)))
+	if err := addFile(path, result); err != nil {
+		return model.SkipNullValue, err
 	}
-	return fmt.Sprintf("{%s}", strings.Join(outputSnippets, "\n")), nil
+	return fmt.Sprintf("import \"%s\"", path), nil
 }
 
-func buildOneDefinition(evaluate model.Evaluate, definition *model.Definition) (string, error) {
+func buildOneDefinition(evaluate model.Evaluate, addFile model.AddFile, definition *model.Definition) (string, error) {
 	if definition == nil {
 		return model.SkipNullValue, errors.New("definition is nil")
 	}
-	var defData string
-	for i, data := range definition.ListOfData {
-		if i > 0 {
-			defData += ",\n"
-		}
-		defData += string(data.Content)
+	var listOfContent []string
+	for _, data := range definition.ListOfData {
+		listOfContent = append(listOfContent, string(data.Content))
 	}
-	defSnippet := fmt.Sprintf("[%s]", defData)
+	defSnippet := fmt.Sprintf("[%s]", strings.Join(listOfContent, ",\n"))
 	if definition.FunctionData != nil {
-		defSnippet = fmt.Sprintf(`
-/*
-The line below is a place to put all data of a definition. The definitions
-here is the one taken from "definition.Path" if it is specified.
-
-The generated definition will follow:
-	local definition = [...]; // an array
-
-Detail:
-	* variable name: "definition"
-	* variable value: array of object
-
-Example:
-	local definition = [
-		{ "name": "lion" },
-		{ "name": "cow" },
-	];
-*/
-local definition = %s;
-
-/*
-The line below is a place to put the function for definition. This function
-is usually for constructing a new definition based on the definition
-above. The function is provided by the user.
-
-The format should follow:
-	local construct(definition) = {...}; // an object
-	or
-	local construct(definition) = [...]; // an array
-
-Detail:
-	* function name: "construct"
-	* argument: definition generated from the "definition.Path"
-	* return: an object or an array
-
-Example:
-	local construct(definition) = [
-		{ name: d.name, isChecked: true }
-		for d in definition
-	];
-The example above adds field "isChecked" for each definition.
-*/
-%s
-
-/*
-The line below is to call the defined function. It requires the definition
-being defined. The definition is then passed as argument. The user does
-not need to specify it.
-
-The format should follow:
-	construct(definition)
-*/
-construct (definition)
-
-`,
-			defSnippet,
-			string(definition.FunctionData.Content),
+		if err := addFile(definition.FunctionData.Path, buildFunctionFile(definition.FunctionData, constructFunctionName)); err != nil {
+			return model.SkipNullValue, err
+		}
+		result, err := evaluate(
+			definition.FunctionData.Path,
+			buildCallSnippet(definition.FunctionData.Path, []string{definitionArgument}),
+			map[string]string{
+				definitionArgument: defSnippet,
+			},
 		)
-		result, err := evaluate(definition.Name, defSnippet)
 		if err != nil {
 			return model.SkipNullValue, err
 		}
@@ -194,110 +284,43 @@ construct (definition)
 	return defSnippet, nil
 }
 
-func buildSnippet(
-	resourceSnippet string,
-	definitionSnippet string,
-	previousOutputSnippet string,
-	procedure *model.Procedure,
-) (string, error) {
-	if procedure.Data == nil {
-		return model.SkipNullValue, fmt.Errorf("procedure data for [%s] is nil", procedure.Name)
+// buildFunctionFile builds the file of a procedure or a definition function, which results in its function.
+// A file written in the earlier form only defines the function as a local variable, which is a syntax error
+// at the end of the file, so the name of the function is appended to result in it without moving any position.
+func buildFunctionFile(data *model.Data, functionName string) string {
+	content := string(data.Content)
+	_, err := jsonnet.SnippetToAST(data.Path, content)
+	if err == nil || !isEndOfFileError(content, err) {
+		return content
 	}
-	output := fmt.Sprintf(`
-/*
-The line below is to declare the resource to be evaluated. This is auto-generated
-taken from "resource.Path". The user only needs to define the resource itself.
-
-The generated resource follow:
-	local resource = {...}; // an object
-
-Detail:
-	* variable name: "resource"
-	* variabl value: an object
-
-Example:
-	local resource = {"name": "unknown"};
-*/
-local resource = %s;
-
-/*
-The line below is to declare the definition. The definition is taken from the
-defined definition, be it directly from the "definition.Path" or one that is
-constructed. Since there can be multiple definitions being provided by the user,
-with different name, then the definition here will be an object. This object
-has key, which is taken from "definition.Name", and the value of object or array.
-
-The format will follow:
-	local definition = {
-		"definition.Name": {...} // an object
-		or
-		"definition.Name": [...] // an array
-	} // should be object
-
-Detail:
-	* variable name: "definition"
-	* variable value: object of object or object of array
+	return content + "\n" + functionName + "\n"
+}
 
-Example:
-	local definition = {
-		"animals": [...]
+func isEndOfFileError(content string, err error) bool {
+	e, ok := err.(interface{ Loc() ast.LocationRange })
+	if !ok {
+		return false
 	}
-*/
-local definition = %s;
-
-/*
-The line below is to declare the previous output. Previous output
-should be declared, even if the previous procedure does return
-empty or even if the prevous result does not return anything.
-If such case is encountered, then it should be set to be null value.
-
-The format will follow:
-	local previousOutput = {...}; // an object
-	or
-	local previousOutput = [...]; // an array
-
-Detail:
-	* variable name: "previousOutput"
-	* varialbe value: an object or an array
-*/
-local previousOutput = %s;
-
-/*
-Line below is to declare the procedure defined by the user.
-
-The format should follow:
-	local evaluate(resource, definition, previousOutput) = {}; // should return object
-	or
-	local evaluate(resource, definition, previousOutput) = []; // should return array
-
-Detail:
-	* procedure name: "evaluate"
-	* first argument: resource to be evaluated
-	* second argument: definition to be used for evaluation
-	* third argument: previous output
-	* return: an objet or an array
-
-Example:
-	local evaluate(resource, definition, previousOutput) = {
-		"valid": true,
+	lastLine := content[strings.LastIndex(content, "\n")+1:]
+	endOfFile := ast.Location{
+		Line:   strings.Count(content, "\n") + 1,
+		Column: utf8.RuneCountInString(lastLine) + 1,
 	}
-*/
-%s
-
-/*
-The line below is to call the defined procedure. The user does not need to
-define it as it is generated.
+	return e.Loc().Begin == endOfFile
+}
 
-The format should follow:
-	evaluate(resource, definition, previousOutput)
-*/
-evaluate (resource, definition, previousOutput)
+// buildCallSnippet builds the snippet importing the file of the path, then calling its function with the arguments
+// in the given order. The file is imported by its name, which is resolved relative to the path the snippet is evaluated at.
+func buildCallSnippet(path string, argumentNames []string) string {
+	name, _ := json.Marshal(filepath.Base(path))
+	return fmt.Sprintf("%s(import %s)(%s)", buildArgumentsHeader(), name, strings.Join(argumentNames, ", "))
+}
 
-`,
-		resourceSnippet,
-		definitionSnippet,
-		previousOutputSnippet,
-		string(procedure.Data.Content),
-	)
-	return output, nil
+// buildArgumentsHeader builds a function accepting every argument as a top-level argument
+func buildArgumentsHeader() string {
+	parameters := make([]string, len(procedureArguments))
+	for i, name := range procedureArguments {
+		parameters[i] = fmt.Sprintf("%s=%s", name, resetArgumentCode)
+	}
+	return fmt.Sprintf("function(%s) ", strings.Join(parameters, ", "))
 }
@@ -2,16 +2,40 @@ package core_test
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/gojek/optimus-extension-valor/core"
 	"github.com/gojek/optimus-extension-valor/model"
 	_ "github.com/gojek/optimus-extension-valor/plugin/endec"
 
+	"github.com/google/go-jsonnet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
+// ignoreFile adds nothing, which is enough for evaluate that never imports the added files
+func ignoreFile(path, content string) error {
+	return nil
+}
+
+// newEvaluateJsonnet creates evaluate along with add file of the importer shared by every evaluation,
+// where each evaluation creates its own VM and parses the snippet again
+func newEvaluateJsonnet() (model.Evaluate, model.AddFile) {
+	importer := core.NewImporter(nil)
+	evaluate := func(path, snippet string, arguments map[string]string) (string, error) {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		core.RegisterNativeFunctions(vm)
+		for name, code := range arguments {
+			vm.TLACode(name, code)
+		}
+		return vm.EvaluateSnippet(path, snippet)
+	}
+	return evaluate, importer.Add
+}
+
 type EvaluatorSuite struct {
 	suite.Suite
 }
@@ -20,10 +44,10 @@ func (e *EvaluatorSuite) TestEvaluate() {
 	e.Run("should return false and error if resource data is nil", func() {
 		framework := &model.Framework{}
 		var resourceData *model.Data = nil
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
-		evaluator, _ := core.NewEvaluator(framework, evaluate)
+		evaluator, _ := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		expectedValue := false
 
@@ -38,10 +62,10 @@ func (e *EvaluatorSuite) TestEvaluate() {
 			Procedures: []*model.Procedure{nil},
 		}
 		var resourceData *model.Data = &model.Data{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
-		evaluator, _ := core.NewEvaluator(framework, evaluate)
+		evaluator, _ := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		expectedValue := false
 
@@ -60,10 +84,10 @@ func (e *EvaluatorSuite) TestEvaluate() {
 			},
 		}
 		var resourceData *model.Data = &model.Data{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
-		evaluator, _ := core.NewEvaluator(framework, evaluate)
+		evaluator, _ := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		expectedValue := false
 
@@ -85,10 +109,10 @@ func (e *EvaluatorSuite) TestEvaluate() {
 			},
 		}
 		var resourceData *model.Data = &model.Data{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", errors.New("test error")
 		}
-		evaluator, _ := core.NewEvaluator(framework, evaluate)
+		evaluator, _ := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		expectedValue := false
 
@@ -110,10 +134,10 @@ func (e *EvaluatorSuite) TestEvaluate() {
 			},
 		}
 		var resourceData *model.Data = &model.Data{}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "{\"message\": \"error\"}", nil
 		}
-		evaluator, _ := core.NewEvaluator(framework, evaluate)
+		evaluator, _ := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		expectedValue := true
 
@@ -122,6 +146,271 @@ func (e *EvaluatorSuite) TestEvaluate() {
 		e.Equal(expectedValue, actualValue)
 		e.Nil(actualErr)
 	})
+
+	e.Run("should expose outputs of the procedures listed under inputs", func() {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name: "first",
+					Data: &model.Data{
+						Path:    "first.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) = { value: 1 };"),
+					},
+				},
+				{
+					Name: "second",
+					Data: &model.Data{
+						Path:    "second.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) = { value: 2 };"),
+					},
+				},
+				{
+					Name:   "third",
+					Inputs: []string{"first", "second"},
+					Data: &model.Data{
+						Path:    "third.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput, outputs) = { value: outputs.first.value + outputs.second.value };"),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Content: []byte("{}"),
+		}
+		pathToResult := make(map[string]string)
+		evaluateFile, addFile := newEvaluateJsonnet()
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			result, err := evaluateFile(path, snippet, arguments)
+			pathToResult[path] = result
+			return result, err
+		}
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedResult := "{\n   \"value\": 3\n}\n"
+
+		actualValue, actualErr := evaluator.Evaluate(resourceData)
+
+		e.True(actualValue)
+		e.Nil(actualErr)
+		e.Equal(expectedResult, pathToResult["third.jsonnet"])
+	})
+
+	e.Run("should quote the names of the outputs and the definitions", func() {
+		framework := &model.Framework{
+			Definitions: []*model.Definition{
+				{
+					Name: `fac"tor\`,
+					ListOfData: []*model.Data{
+						{
+							Path:    "factor.json",
+							Content: []byte(`{"value": 2}`),
+						},
+					},
+				},
+			},
+			Procedures: []*model.Procedure{
+				{
+					Name: `fi"rst\`,
+					Data: &model.Data{
+						Path:    "first.jsonnet",
+						Content: []byte("function(resource, definition, previousOutput) { value: 1 }"),
+					},
+				},
+				{
+					Name:   "second",
+					Inputs: []string{`fi"rst\`},
+					Data: &model.Data{
+						Path:    "second.jsonnet",
+						Content: []byte("function(resource, definition, previousOutput, outputs) { value: outputs['fi\"rst\\\\'].value + definition['fac\"tor\\\\'][0].value }"),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Content: []byte("{}"),
+		}
+		pathToResult := make(map[string]string)
+		evaluateFile, addFile := newEvaluateJsonnet()
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			result, err := evaluateFile(path, snippet, arguments)
+			pathToResult[path] = result
+			return result, err
+		}
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedResult := "{\n   \"value\": 3\n}\n"
+
+		actualValue, actualErr := evaluator.Evaluate(resourceData)
+
+		e.True(actualValue)
+		e.Nil(actualErr)
+		e.Equal(expectedResult, pathToResult["second.jsonnet"])
+	})
+
+	e.Run("should skip procedure and keep previous output if when condition is false", func() {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name: "first",
+					Data: &model.Data{
+						Path:    "first.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) = { value: 1 };"),
+					},
+				},
+				{
+					Name: "second",
+					When: "resource.is_active && previousOutput.value > 1",
+					Data: &model.Data{
+						Path:    "second.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) = { value: 2 };"),
+					},
+				},
+				{
+					Name: "third",
+					Data: &model.Data{
+						Path:    "third.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) = previousOutput;"),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Path:    "resource.json",
+			Content: []byte(`{"is_active": true}`),
+		}
+		pathToResult := make(map[string]string)
+		evaluateFile, addFile := newEvaluateJsonnet()
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			result, err := evaluateFile(path, snippet, arguments)
+			pathToResult[path] = result
+			return result, err
+		}
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedResult := "{\n   \"value\": 1\n}\n"
+		expectedSkipped := []*model.SkippedStep{
+			{Type: "procedure", Name: "second", Paths: []string{"resource.json"}},
+		}
+
+		actualValue, actualErr := evaluator.Evaluate(resourceData)
+
+		e.True(actualValue)
+		e.Nil(actualErr)
+		e.Equal(expectedResult, pathToResult["third.jsonnet"])
+		e.Equal(expectedSkipped, evaluator.Skipped())
+	})
+
+	e.Run("should evaluate definition once and reuse it for every resource", func() {
+		framework := &model.Framework{
+			Definitions: []*model.Definition{
+				{
+					Name: "factor",
+					ListOfData: []*model.Data{
+						{
+							Path:    "factor.json",
+							Content: []byte("{\n  \"value\": 3\n}"),
+						},
+					},
+				},
+			},
+			Procedures: []*model.Procedure{
+				{
+					Name: "multiply",
+					When: "definition.factor[0].value > 0",
+					Data: &model.Data{
+						Path:    "multiply.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) =\n  { value: resource.n * definition.factor[0].value };"),
+					},
+				},
+			},
+		}
+		pathToCounter := make(map[string]int)
+		pathToResult := make(map[string]string)
+		var snippets []string
+		evaluateFile, addFile := newEvaluateJsonnet()
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			result, err := evaluateFile(path, snippet, arguments)
+			pathToCounter[path]++
+			pathToResult[path] = result
+			if path != "definition" {
+				snippets = append(snippets, snippet)
+			}
+			return result, err
+		}
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedResult := "{\n   \"value\": 6\n}\n"
+		expectedDefinitionCounter := 1
+		expectedProcedureCounter := 2
+
+		evaluator.Evaluate(&model.Data{Content: []byte(`{"n": 1}`)})
+		actualValue, actualErr := evaluator.Evaluate(&model.Data{Content: []byte(`{"n": 2}`)})
+
+		e.True(actualValue)
+		e.Nil(actualErr)
+		e.Equal(expectedResult, pathToResult["multiply.jsonnet"])
+		e.Equal(expectedDefinitionCounter, pathToCounter["definition"])
+		e.Equal(expectedProcedureCounter, pathToCounter["multiply.jsonnet"])
+		for _, snippet := range snippets {
+			e.NotContains(snippet, `"factor"`)
+		}
+	})
+
+	e.Run("should report error against the path and line of the procedure file", func() {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name: "broken",
+					Data: &model.Data{
+						Path:    "broken.jsonnet",
+						Content: []byte("local evaluate(resource, definition, previousOutput) =\n  resource.unknown;"),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Path:    "resource.json",
+			Content: []byte(`{"message": "local evaluate = null;"}`),
+		}
+		evaluate, addFile := newEvaluateJsonnet()
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedLocation := "broken.jsonnet:2:3-19"
+
+		actualValue, actualErr := evaluator.Evaluate(resourceData)
+
+		e.False(actualValue)
+		e.Require().NotNil(actualErr)
+		e.Contains(actualErr.Error(), expectedLocation)
+	})
+
+	e.Run("should report error against the column of the first line of the procedure file", func() {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name: "broken",
+					Data: &model.Data{
+						Path:    "broken.jsonnet",
+						Content: []byte("function(resource, definition, previousOutput) resource.unknown"),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Path:    "resource.json",
+			Content: []byte(`{}`),
+		}
+		evaluate, addFile := newEvaluateJsonnet()
+		evaluator, _ := core.NewEvaluator(framework, evaluate, addFile)
+
+		expectedLocation := "broken.jsonnet:1:48-64"
+
+		actualValue, actualErr := evaluator.Evaluate(resourceData)
+
+		e.False(actualValue)
+		e.Require().NotNil(actualErr)
+		e.Contains(actualErr.Error(), expectedLocation)
+	})
 }
 
 func TestEvaluatorSuite(t *testing.T) {
@@ -131,11 +420,11 @@ func TestEvaluatorSuite(t *testing.T) {
 func TestNewEvaluator(t *testing.T) {
 	t.Run("should return nil and error if framework is nil", func(t *testing.T) {
 		var framework *model.Framework = nil
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 
-		actualValue, actualErr := core.NewEvaluator(framework, evaluate)
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -145,7 +434,69 @@ func TestNewEvaluator(t *testing.T) {
 		framework := &model.Framework{}
 		var evaluate model.Evaluate = nil
 
-		actualValue, actualErr := core.NewEvaluator(framework, evaluate)
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
+
+		assert.Nil(t, actualValue)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if add file is nil", func(t *testing.T) {
+		framework := &model.Framework{}
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			return "", nil
+		}
+		var addFile model.AddFile = nil
+
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, addFile)
+
+		assert.Nil(t, actualValue)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if procedure cannot be added as a file", func(t *testing.T) {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name: "first",
+					Data: &model.Data{
+						Path:    "procedure.jsonnet",
+						Content: []byte("function(resource, definition, previousOutput) 1"),
+					},
+				},
+				{
+					Name: "second",
+					Data: &model.Data{
+						Path:    "procedure.jsonnet",
+						Content: []byte("function(resource, definition, previousOutput) 2"),
+					},
+				},
+			},
+		}
+		evaluate, addFile := newEvaluateJsonnet()
+
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, addFile)
+
+		assert.Nil(t, actualValue)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if input does not refer to an earlier procedure", func(t *testing.T) {
+		framework := &model.Framework{
+			Procedures: []*model.Procedure{
+				{
+					Name:   "first",
+					Inputs: []string{"second"},
+				},
+				{
+					Name: "second",
+				},
+			},
+		}
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
+			return "", nil
+		}
+
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -160,11 +511,11 @@ func TestNewEvaluator(t *testing.T) {
 				nil,
 			},
 		}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 
-		actualValue, actualErr := core.NewEvaluator(framework, evaluate)
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -186,11 +537,11 @@ func TestNewEvaluator(t *testing.T) {
 				},
 			},
 		}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", errors.New("test error")
 		}
 
-		actualValue, actualErr := core.NewEvaluator(framework, evaluate)
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -211,13 +562,127 @@ func TestNewEvaluator(t *testing.T) {
 				},
 			},
 		}
-		var evaluate model.Evaluate = func(name, snippet string) (string, error) {
+		var evaluate model.Evaluate = func(path, snippet string, arguments map[string]string) (string, error) {
 			return "", nil
 		}
 
-		actualValue, actualErr := core.NewEvaluator(framework, evaluate)
+		actualValue, actualErr := core.NewEvaluator(framework, evaluate, ignoreFile)
 
 		assert.NotNil(t, actualValue)
 		assert.Nil(t, actualErr)
 	})
 }
+
+// BenchmarkEvaluate evaluates a resource against a framework with a large definition,
+// where parsing every snippet on each evaluation is compared to the pool that parses it once
+func BenchmarkEvaluate(b *testing.B) {
+	var memberships []string
+	for i := 0; i < 1000; i++ {
+		memberships = append(memberships, fmt.Sprintf(`{"id": %d, "name": "membership_%d"}`, i, i))
+	}
+	framework := &model.Framework{
+		Definitions: []*model.Definition{
+			{
+				Name: "memberships",
+				ListOfData: []*model.Data{
+					{
+						Path:    "memberships.json",
+						Content: []byte("[" + strings.Join(memberships, ",") + "]"),
+					},
+				},
+				FunctionData: &model.Data{
+					Path:    "memberships.jsonnet",
+					Content: []byte("local construct(definition) = { [std.toString(d.id)]: d for d in definition[0] };"),
+				},
+			},
+		},
+		Procedures: []*model.Procedure{
+			{
+				Name: "enrich",
+				Data: &model.Data{
+					Path:    "enrich.jsonnet",
+					Content: []byte("local evaluate(resource, definition, previousOutput) = resource { membership: definition.memberships[std.toString(resource.membership_id)].name };"),
+				},
+			},
+			{
+				Name: "score",
+				When: "previousOutput.membership != null",
+				Data: &model.Data{
+					Path:    "score.jsonnet",
+					Content: []byte("local evaluate(resource, definition, previousOutput) = previousOutput { score: std.length(previousOutput.membership) };"),
+				},
+			},
+		},
+	}
+	resourceData := &model.Data{
+		Path:    "resource.json",
+		Content: []byte(`{"email": "lorem@ipsum.com", "membership_id": 42}`),
+	}
+	importer := core.NewImporter(nil)
+	pool, err := core.NewVMPool(1, func() *jsonnet.VM {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		return vm
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	pastePool, err := core.NewVMPool(1, func() *jsonnet.VM {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		return vm
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	evaluateJsonnet, addFile := newEvaluateJsonnet()
+	nameToEvaluate := map[string]model.Evaluate{
+		"parse_every_evaluation":     evaluateJsonnet,
+		"paste_definition_with_pool": newPasteDefinitionEvaluate(pastePool.Evaluate),
+		"parse_once_with_pool":       pool.Evaluate,
+	}
+	nameToAddFile := map[string]model.AddFile{
+		"parse_every_evaluation":     addFile,
+		"paste_definition_with_pool": importer.Add,
+		"parse_once_with_pool":       importer.Add,
+	}
+	for _, name := range []string{"parse_every_evaluation", "paste_definition_with_pool", "parse_once_with_pool"} {
+		evaluator, err := core.NewEvaluator(framework, nameToEvaluate[name], nameToAddFile[name])
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := evaluator.Evaluate(resourceData); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// newPasteDefinitionEvaluate evaluates the way the definition used to be passed, where the evaluated definition
+// is pasted into every snippet as a literal instead of being passed as an argument
+func newPasteDefinitionEvaluate(evaluate model.Evaluate) model.Evaluate {
+	var definition string
+	return func(path, snippet string, arguments map[string]string) (string, error) {
+		if path == "definition" {
+			result, err := evaluate(path, snippet, arguments)
+			definition = result
+			return result, err
+		}
+		// the definition function is called before the definition is evaluated
+		if _, ok := arguments["definition"]; !ok || definition == "" {
+			return evaluate(path, snippet, arguments)
+		}
+		nameToCode := make(map[string]string)
+		for name, code := range arguments {
+			if name != "definition" {
+				nameToCode[name] = code
+			}
+		}
+		header := snippet[:strings.Index(snippet, ") ")+2]
+		pasted := header + "local definition = " + strings.ReplaceAll(definition, "\n", " ") + "; " + snippet[len(header):]
+		return evaluate(path, pasted, nameToCode)
+	}
+}
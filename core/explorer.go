@@ -4,12 +4,19 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
 	"github.com/gojek/optimus-extension-valor/registry/explorer"
 )
 
 // ExplorePaths explores the given root path for the type and format
 func ExplorePaths(rootPath, _type, format, regexPattern string) ([]string, error) {
-	exPath, err := explorer.Explorers.Get(_type)
+	return ExplorePathsWithOption(rootPath, _type, format, regexPattern, nil)
+}
+
+// ExplorePathsWithOption explores the given root path for the type and format based on the explore recipe
+func ExplorePathsWithOption(rootPath, _type, format, regexPattern string, rcp *recipe.Explore) ([]string, error) {
+	exPath, err := explorer.Explorers.GetWithOption(_type, convertExplore(rcp))
 	if err != nil {
 		return nil, err
 	}
@@ -21,3 +28,14 @@ func ExplorePaths(rootPath, _type, format, regexPattern string) ([]string, error
 		return reg.MatchString(path) && strings.HasSuffix(path, format)
 	})
 }
+
+func convertExplore(rcp *recipe.Explore) *model.ExploreOption {
+	if rcp == nil {
+		return nil
+	}
+	return &model.ExploreOption{
+		Recursive: rcp.Recursive,
+		MaxDepth:  rcp.MaxDepth,
+		Symlink:   model.SymlinkPolicy(rcp.Symlink),
+	}
+}
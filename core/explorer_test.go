@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
 	"github.com/gojek/optimus-extension-valor/registry/explorer"
 
 	"github.com/stretchr/testify/assert"
@@ -73,3 +75,55 @@ func TestExplorePaths(t *testing.T) {
 		assert.NoError(t, actualErr)
 	})
 }
+
+func TestExplorePathsWithOption(t *testing.T) {
+	originalExplorer := explorer.Explorers
+	defer func() { explorer.Explorers = originalExplorer }()
+	explorer.Explorers = explorer.NewFactory()
+
+	const (
+		rootPath = "."
+		format   = "go"
+		pattern  = ""
+	)
+
+	explorer.Explorers.Register("virtual", func(root string, filter func(string) bool) ([]string, error) {
+		return []string{"./core/testing/root.go"}, nil
+	})
+	var actualOption *model.ExploreOption
+	explorer.Explorers.RegisterWithOption("virtual_dir", func(option *model.ExploreOption) model.ExplorePath {
+		actualOption = option
+		return func(root string, filter func(string) bool) ([]string, error) {
+			return []string{"./core/testing/root.go"}, nil
+		}
+	})
+
+	t.Run("should return nil and error if explore recipe is set for explorer without option", func(t *testing.T) {
+		rcp := &recipe.Explore{Recursive: true}
+
+		actualPaths, actualErr := core.ExplorePathsWithOption(rootPath, "virtual", format, pattern, rcp)
+
+		assert.Nil(t, actualPaths)
+		assert.Error(t, actualErr)
+	})
+
+	t.Run("should pass converted option and return paths if explorer accepts option", func(t *testing.T) {
+		rcp := &recipe.Explore{
+			Recursive: true,
+			MaxDepth:  2,
+			Symlink:   "follow",
+		}
+
+		expectedOption := &model.ExploreOption{
+			Recursive: true,
+			MaxDepth:  2,
+			Symlink:   model.SymlinkFollow,
+		}
+
+		actualPaths, actualErr := core.ExplorePathsWithOption(rootPath, "virtual_dir", format, pattern, rcp)
+
+		assert.Len(t, actualPaths, 1)
+		assert.NoError(t, actualErr)
+		assert.EqualValues(t, expectedOption, actualOption)
+	})
+}
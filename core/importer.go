@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-jsonnet"
+)
+
+// nativeLibraryPath is the path to import the valor object from, where it is served from memory
+const nativeLibraryPath = nativeObjectName + ".libsonnet"
+
+// Importer resolves an import into a file served from memory, such as the valor library,
+// before searching the importing directory and the library paths
+type Importer struct {
+	nativeLibrary jsonnet.Contents
+
+	mtx            *sync.RWMutex
+	pathToContents map[string]jsonnet.Contents
+
+	// fileMtx guards fileImporter, which caches every file it reads without any lock
+	fileMtx      *sync.Mutex
+	fileImporter *jsonnet.FileImporter
+}
+
+// NewImporter initializes Importer, where the library paths are searched in the order they are given.
+// Importer is safe to be shared by every VM in a pool.
+func NewImporter(libraryPaths []string) *Importer {
+	// file importer searches the library paths from the last one
+	var jPaths []string
+	for i := len(libraryPaths) - 1; i >= 0; i-- {
+		jPaths = append(jPaths, libraryPaths[i])
+	}
+	return &Importer{
+		nativeLibrary:  jsonnet.MakeContents(buildNativeObjectSnippet()),
+		mtx:            &sync.RWMutex{},
+		pathToContents: make(map[string]jsonnet.Contents),
+		fileMtx:        &sync.Mutex{},
+		fileImporter: &jsonnet.FileImporter{
+			JPaths: jPaths,
+		},
+	}
+}
+
+// Add adds a file to be served from memory, where the path is relative to the working directory.
+// Adding the same content again does nothing, but a different content for the same path results in error,
+// since every VM caches an imported file by its path.
+func (i *Importer) Add(path, content string) error {
+	path = filepath.Clean(path)
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if existing, ok := i.pathToContents[path]; ok {
+		if existing.String() != content {
+			return fmt.Errorf("file [%s] is already added with a different content", path)
+		}
+		return nil
+	}
+	i.pathToContents[path] = jsonnet.MakeContents(content)
+	return nil
+}
+
+// Import imports the file added next to the importing file if any, then the valor library,
+// otherwise the file found on the disk.
+func (i *Importer) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	foundAt := importedPath
+	if !filepath.IsAbs(importedPath) {
+		foundAt = filepath.Join(filepath.Dir(importedFrom), importedPath)
+	}
+	i.mtx.RLock()
+	contents, ok := i.pathToContents[foundAt]
+	i.mtx.RUnlock()
+	if ok {
+		return contents, foundAt, nil
+	}
+	if importedPath == nativeLibraryPath {
+		return i.nativeLibrary, nativeLibraryPath, nil
+	}
+	i.fileMtx.Lock()
+	defer i.fileMtx.Unlock()
+	return i.fileImporter.Import(importedFrom, importedPath)
+}
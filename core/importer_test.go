@@ -0,0 +1,136 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+
+	"github.com/google/go-jsonnet"
+	"github.com/stretchr/testify/suite"
+)
+
+const importerDirName = "./importer"
+
+type ImporterSuite struct {
+	suite.Suite
+}
+
+func (i *ImporterSuite) SetupSuite() {
+	nameToContent := map[string]string{
+		"first/common.libsonnet":  "'first'",
+		"second/common.libsonnet": "'second'",
+		"second/util.libsonnet":   "local valor = import 'valor.libsonnet'; { isDigits(str): valor.regexMatch('^[0-9]+$', str) }",
+		"local/common.libsonnet":  "'local'",
+	}
+	for name, content := range nameToContent {
+		filePath := path.Join(importerDirName, name)
+		if err := os.MkdirAll(path.Dir(filePath), os.ModePerm); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (i *ImporterSuite) TestImport() {
+	libraryPaths := []string{path.Join(importerDirName, "first"), path.Join(importerDirName, "second")}
+
+	i.Run("should let a library import the valor library", func() {
+		vm := i.newVM(libraryPaths)
+
+		expectedResult := "true\n"
+
+		actualResult, actualErr := vm.EvaluateSnippet("snippet.jsonnet", "(import 'util.libsonnet').isDigits('123')")
+
+		i.Equal(expectedResult, actualResult)
+		i.Nil(actualErr)
+	})
+
+	i.Run("should search the library paths in the order they are given", func() {
+		vm := i.newVM(libraryPaths)
+
+		expectedResult := "\"first\"\n"
+
+		actualResult, actualErr := vm.EvaluateSnippet("snippet.jsonnet", "import 'common.libsonnet'")
+
+		i.Equal(expectedResult, actualResult)
+		i.Nil(actualErr)
+	})
+
+	i.Run("should search the importing directory before the library paths", func() {
+		vm := i.newVM(libraryPaths)
+		snippetPath := path.Join(importerDirName, "local", "snippet.jsonnet")
+
+		expectedResult := "\"local\"\n"
+
+		actualResult, actualErr := vm.EvaluateSnippet(snippetPath, "import 'common.libsonnet'")
+
+		i.Equal(expectedResult, actualResult)
+		i.Nil(actualErr)
+	})
+
+	i.Run("should return error if the import is not found", func() {
+		vm := i.newVM(libraryPaths)
+
+		actualResult, actualErr := vm.EvaluateSnippet("snippet.jsonnet", "import 'unknown.libsonnet'")
+
+		i.Empty(actualResult)
+		i.NotNil(actualErr)
+	})
+}
+
+func (i *ImporterSuite) TestAdd() {
+	i.Run("should serve the added file to the snippet importing it relatively", func() {
+		importer := core.NewImporter(nil)
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		snippetPath := path.Join(importerDirName, "local", "snippet.jsonnet")
+
+		expectedResult := "\"added\"\n"
+
+		actualErr := importer.Add(path.Join(importerDirName, "local", "common.libsonnet"), "'added'")
+		actualResult, _ := vm.EvaluateSnippet(snippetPath, "import 'common.libsonnet'")
+
+		i.Nil(actualErr)
+		i.Equal(expectedResult, actualResult)
+	})
+
+	i.Run("should return nil if the same content is added again", func() {
+		importer := core.NewImporter(nil)
+		importer.Add("procedure.jsonnet", "'content'")
+
+		actualErr := importer.Add("./procedure.jsonnet", "'content'")
+
+		i.Nil(actualErr)
+	})
+
+	i.Run("should return error if a different content is added to the same path", func() {
+		importer := core.NewImporter(nil)
+		importer.Add("procedure.jsonnet", "'content'")
+
+		actualErr := importer.Add("./procedure.jsonnet", "'different'")
+
+		i.NotNil(actualErr)
+	})
+}
+
+func (i *ImporterSuite) newVM(libraryPaths []string) *jsonnet.VM {
+	vm := jsonnet.MakeVM()
+	vm.Importer(core.NewImporter(libraryPaths))
+	core.RegisterNativeFunctions(vm)
+	return vm
+}
+
+func (i *ImporterSuite) TearDownSuite() {
+	if err := os.RemoveAll(importerDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestImporterSuite(t *testing.T) {
+	suite.Run(t, new(ImporterSuite))
+}
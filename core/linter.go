@@ -0,0 +1,248 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+const (
+	lintRuleLoad              = "load"
+	lintRuleSyntax            = "jsonnet-syntax"
+	lintRuleProcedureFunction = "procedure-function"
+	lintRuleDefinitionFunc    = "definition-function"
+	lintRuleWhenCondition     = "when-condition"
+
+	evaluateFunctionName         = "evaluate"
+	evaluateSignature            = "evaluate(resource, definition, previousOutput)"
+	evaluateWithOutputsSignature = "evaluate(resource, definition, previousOutput, outputs)"
+	constructFunctionName        = "construct"
+	constructSignature           = "construct(definition)"
+)
+
+// Linter lints the Jsonnet files referenced by a recipe without executing them
+type Linter struct {
+	loader *Loader
+}
+
+// NewLinter initializes Linter
+func NewLinter() *Linter {
+	return &Linter{
+		loader: &Loader{},
+	}
+}
+
+// LintFrameworks lints every procedure, definition function, and when condition within the frameworks.
+// Each file is only linted once, even if it is referenced multiple times.
+func (l *Linter) LintFrameworks(rcps []*recipe.Framework) []*model.Issue {
+	var output []*model.Issue
+	linted := make(map[string]bool)
+	for _, frameworkRcp := range rcps {
+		if frameworkRcp == nil {
+			continue
+		}
+		for _, schemaRcp := range frameworkRcp.Schemas {
+			if schemaRcp != nil {
+				output = append(output, LintCondition(schemaRcp.Path, schemaRcp.When, schemaConditionVariables)...)
+			}
+		}
+		for _, procedureRcp := range frameworkRcp.Procedures {
+			if procedureRcp != nil {
+				output = append(output, LintCondition(procedureRcp.Path, procedureRcp.When, procedureConditionVariables)...)
+			}
+			if procedureRcp == nil {
+				continue
+			}
+			// a procedure with inputs is linted against a different signature
+			key := procedureRcp.Path
+			if len(procedureRcp.Inputs) > 0 {
+				key += "#" + outputsArgument
+			}
+			if linted[key] {
+				continue
+			}
+			linted[key] = true
+			procedure, err := l.loader.LoadProcedure(procedureRcp)
+			if err != nil {
+				output = append(output, newLoadIssue(procedureRcp.Path, err))
+				continue
+			}
+			output = append(output, LintProcedure(procedure)...)
+		}
+		for _, definitionRcp := range frameworkRcp.Definitions {
+			if definitionRcp == nil || definitionRcp.Function == nil || linted[definitionRcp.Function.Path] {
+				continue
+			}
+			linted[definitionRcp.Function.Path] = true
+			data, err := l.loader.LoadData(definitionRcp.Function.Path, definitionRcp.Function.Type, jsonnetFormat)
+			if err != nil {
+				output = append(output, newLoadIssue(definitionRcp.Function.Path, err))
+				continue
+			}
+			output = append(output, LintDefinitionFunction(data)...)
+		}
+	}
+	return output
+}
+
+// LintProcedure lints a procedure, which should result in evaluate function accepting three arguments,
+// or four arguments if the procedure has inputs
+func LintProcedure(procedure *model.Procedure) []*model.Issue {
+	if procedure == nil {
+		return []*model.Issue{newLoadIssue("", errors.New("procedure is nil"))}
+	}
+	if len(procedure.Inputs) > 0 {
+		return lintFunction(procedure.Data, lintRuleProcedureFunction, evaluateFunctionName, evaluateWithOutputsSignature, 4)
+	}
+	return lintFunction(procedure.Data, lintRuleProcedureFunction, evaluateFunctionName, evaluateSignature, 3)
+}
+
+// LintDefinitionFunction lints a definition function, which should result in construct function accepting one argument
+func LintDefinitionFunction(data *model.Data) []*model.Issue {
+	return lintFunction(data, lintRuleDefinitionFunc, constructFunctionName, constructSignature, 1)
+}
+
+// LintCondition lints the when condition of a schema or a procedure, where only
+// the variables provided by Valor are allowed to be referred to
+func LintCondition(path, when string, variableNames []string) []*model.Issue {
+	if strings.TrimSpace(when) == "" {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("local %s = null;", nativeObjectName)}
+	for _, name := range variableNames {
+		lines = append(lines, fmt.Sprintf("local %s = null;", name))
+	}
+	lines = append(lines, when)
+	if _, err := jsonnet.SnippetToAST(path, strings.Join(lines, "\n")); err != nil {
+		return []*model.Issue{
+			{
+				Rule:     lintRuleWhenCondition,
+				Severity: model.SeverityError,
+				Path:     path,
+				Message:  fmt.Sprintf("when [%s] is invalid: %v", when, err),
+			},
+		}
+	}
+	return nil
+}
+
+// lintFunction lints the file of a function, where the file results in the function
+// either directly or through a local variable, or only defines it in the earlier form
+func lintFunction(data *model.Data, rule, name, signature string, numOfArgs int) []*model.Issue {
+	if data == nil {
+		return []*model.Issue{newLoadIssue("", fmt.Errorf("data for [%s] is nil", signature))}
+	}
+	content := buildFunctionFile(data, name)
+	node, err := jsonnet.SnippetToAST(data.Path, content)
+	if err != nil {
+		if content != string(data.Content) && !isLocalBindDefined(data, name) {
+			return []*model.Issue{
+				{
+					Rule:     rule,
+					Severity: model.SeverityError,
+					Path:     data.Path,
+					Message:  fmt.Sprintf("function [%s] is not defined", signature),
+				},
+			}
+		}
+		issue := &model.Issue{
+			Rule:     lintRuleSyntax,
+			Severity: model.SeverityError,
+			Path:     data.Path,
+			Message:  err.Error(),
+		}
+		if e, ok := err.(interface{ Loc() ast.LocationRange }); ok {
+			issue.Line = e.Loc().Begin.Line
+			issue.Column = e.Loc().Begin.Column
+		}
+		return []*model.Issue{issue}
+	}
+	result := findResult(node)
+	issue := &model.Issue{
+		Rule:     rule,
+		Severity: model.SeverityError,
+		Path:     data.Path,
+	}
+	if loc := result.Loc(); loc != nil {
+		issue.Line = loc.Begin.Line
+		issue.Column = loc.Begin.Column
+	}
+	function, ok := result.(*ast.Function)
+	if !ok {
+		issue.Message = fmt.Sprintf("[%s] should be a function following [%s]", name, signature)
+		return []*model.Issue{issue}
+	}
+	var requiredParams int
+	for _, p := range function.Parameters {
+		if p.DefaultArg == nil {
+			requiredParams++
+		}
+	}
+	if requiredParams > numOfArgs || len(function.Parameters) < numOfArgs {
+		issue.Message = fmt.Sprintf("[%s] has %d parameter(s) with %d required while %d argument(s) are passed, following [%s]",
+			name, len(function.Parameters), requiredParams, numOfArgs, signature,
+		)
+		return []*model.Issue{issue}
+	}
+	return nil
+}
+
+// isLocalBindDefined checks whether a file in the earlier form defines the function as a local variable
+func isLocalBindDefined(data *model.Data, name string) bool {
+	node, err := jsonnet.SnippetToAST(data.Path, string(data.Content)+"\nnull")
+	return err != nil || findLocalBind(node, name) != nil
+}
+
+// findResult finds the expression a file results in, where a local variable is followed to the value bound to it
+func findResult(node ast.Node) ast.Node {
+	nameToValue := make(map[ast.Identifier]ast.Node)
+	for {
+		local, ok := node.(*ast.Local)
+		if !ok {
+			break
+		}
+		for _, bind := range local.Binds {
+			nameToValue[bind.Variable] = bind.Body
+		}
+		node = local.Body
+	}
+	if v, ok := node.(*ast.Var); ok && nameToValue[v.Id] != nil {
+		return nameToValue[v.Id]
+	}
+	return node
+}
+
+func findLocalBind(node ast.Node, name string) *ast.LocalBind {
+	var output *ast.LocalBind
+	for {
+		local, ok := node.(*ast.Local)
+		if !ok {
+			return output
+		}
+		for i := range local.Binds {
+			if string(local.Binds[i].Variable) == name {
+				output = &local.Binds[i]
+			}
+		}
+		node = local.Body
+	}
+}
+
+func newLoadIssue(path string, err error) *model.Issue {
+	message := err.Error()
+	if e, ok := err.(*model.Error); ok {
+		message = string(e.JSON())
+	}
+	return &model.Issue{
+		Rule:     lintRuleLoad,
+		Severity: model.SeverityError,
+		Path:     path,
+		Message:  message,
+	}
+}
@@ -0,0 +1,278 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintProcedure(t *testing.T) {
+	t.Run("should return issue if procedure is nil", func(t *testing.T) {
+		var procedure *model.Procedure = nil
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+	})
+
+	t.Run("should return issue if data is nil", func(t *testing.T) {
+		procedure := &model.Procedure{}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+	})
+
+	t.Run("should return syntax issue with location if procedure cannot be parsed", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local evaluate(resource, definition, previousOutput) =\n    resource +;"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "jsonnet-syntax", actualIssues[0].Rule)
+		assert.Equal(t, 2, actualIssues[0].Line)
+	})
+
+	t.Run("should return issue if evaluate is not defined", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local process(resource, definition, previousOutput) = resource;"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "procedure-function", actualIssues[0].Rule)
+	})
+
+	t.Run("should return issue if evaluate is not a function", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local evaluate = {};"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "procedure-function", actualIssues[0].Rule)
+	})
+
+	t.Run("should return issue if procedure does not result in a function", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local evaluate(resource, definition, previousOutput) = resource;\n{}"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "procedure-function", actualIssues[0].Rule)
+		assert.Equal(t, 2, actualIssues[0].Line)
+	})
+
+	t.Run("should return issue if evaluate has invalid number of parameters", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local evaluate(resource, definition) = resource;"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "procedure-function", actualIssues[0].Rule)
+		assert.Equal(t, 1, actualIssues[0].Line)
+	})
+
+	t.Run("should return issue if procedure with inputs does not accept outputs", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Inputs: []string{"enrich"},
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("function(resource, definition, previousOutput) resource"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "procedure-function", actualIssues[0].Rule)
+	})
+
+	t.Run("should return nil if procedure with inputs accepts outputs", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Inputs: []string{"enrich"},
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("function(resource, definition, previousOutput, outputs) outputs.enrich"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Nil(t, actualIssues)
+	})
+
+	t.Run("should return syntax issue if procedure refers to valor without importing it", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("function(resource, definition, previousOutput) valor.sha256(resource.email)"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "jsonnet-syntax", actualIssues[0].Rule)
+	})
+
+	t.Run("should return nil if procedure imports the valor object", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local valor = import 'valor.libsonnet';\nfunction(resource, definition, previousOutput) valor.sha256(resource.email)"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Nil(t, actualIssues)
+	})
+
+	t.Run("should return column relative to procedure content", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path:    "procedure.jsonnet",
+				Content: []byte("local evaluate = {};"),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, 1, actualIssues[0].Line)
+		assert.Equal(t, 18, actualIssues[0].Column)
+	})
+
+	t.Run("should return nil if procedure results in evaluate", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path: "procedure.jsonnet",
+				Content: []byte(`
+local helper(value) = value;
+local evaluate(resource, definition, previous) = helper(resource);
+evaluate
+`),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Nil(t, actualIssues)
+	})
+
+	t.Run("should return nil if procedure only defines evaluate in the earlier form", func(t *testing.T) {
+		procedure := &model.Procedure{
+			Data: &model.Data{
+				Path: "procedure.jsonnet",
+				Content: []byte(`
+local helper(value) = value;
+local evaluate(resource, definition, previous) = helper(resource);
+`),
+			},
+		}
+
+		actualIssues := core.LintProcedure(procedure)
+
+		assert.Nil(t, actualIssues)
+	})
+}
+
+func TestLintDefinitionFunction(t *testing.T) {
+	t.Run("should return issue if construct has invalid number of parameters", func(t *testing.T) {
+		data := &model.Data{
+			Path:    "function.jsonnet",
+			Content: []byte("local construct(definition, extra) = definition;"),
+		}
+
+		actualIssues := core.LintDefinitionFunction(data)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "definition-function", actualIssues[0].Rule)
+	})
+
+	t.Run("should return nil if definition function is valid", func(t *testing.T) {
+		data := &model.Data{
+			Path:    "function.jsonnet",
+			Content: []byte("local valor = import 'valor.libsonnet';\nfunction(definition) { [valor.regexReplace('-', d.name, '_')]: d for d in definition }"),
+		}
+
+		actualIssues := core.LintDefinitionFunction(data)
+
+		assert.Nil(t, actualIssues)
+	})
+}
+
+func TestLintCondition(t *testing.T) {
+	t.Run("should return nil if when is empty", func(t *testing.T) {
+		actualIssues := core.LintCondition("procedure.jsonnet", "", []string{"resource"})
+
+		assert.Nil(t, actualIssues)
+	})
+
+	t.Run("should return issue if when refers to unknown variable", func(t *testing.T) {
+		actualIssues := core.LintCondition("schema.json", "previousOutput != null", []string{"resource"})
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "when-condition", actualIssues[0].Rule)
+	})
+
+	t.Run("should return nil if when is valid", func(t *testing.T) {
+		actualIssues := core.LintCondition("procedure.jsonnet", "resource.is_active && outputs.enrich != null && valor.regexMatch('^a', resource.name)", []string{"resource", "outputs"})
+
+		assert.Nil(t, actualIssues)
+	})
+}
+
+func TestLintFrameworks(t *testing.T) {
+	t.Run("should return load issue once if procedure cannot be loaded", func(t *testing.T) {
+		procedureRcp := &recipe.Procedure{
+			Name: "procedure",
+			Type: "invalid_type",
+			Path: "./procedure.jsonnet",
+		}
+		rcps := []*recipe.Framework{
+			{
+				Name:       "framework1",
+				Procedures: []*recipe.Procedure{procedureRcp},
+			},
+			{
+				Name:       "framework2",
+				Procedures: []*recipe.Procedure{procedureRcp},
+			},
+		}
+		linter := core.NewLinter()
+
+		actualIssues := linter.LintFrameworks(rcps)
+
+		assert.Len(t, actualIssues, 1)
+		assert.Equal(t, "load", actualIssues[0].Rule)
+	})
+}
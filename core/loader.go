@@ -92,6 +92,8 @@ func (l *Loader) LoadProcedure(rcp *recipe.Procedure) (*model.Procedure, error)
 	return &model.Procedure{
 		Name:   rcp.Name,
 		Data:   data,
+		Inputs: rcp.Inputs,
+		When:   rcp.When,
 		Output: l.convertOutput(rcp.Output),
 	}, nil
 }
@@ -148,6 +150,7 @@ func (l *Loader) LoadSchema(rcp *recipe.Schema) (*model.Schema, error) {
 	return &model.Schema{
 		Name:   rcp.Name,
 		Data:   data,
+		When:   rcp.When,
 		Output: l.convertOutput(rcp.Output),
 	}, nil
 }
@@ -209,7 +212,7 @@ func (l *Loader) LoadDefinition(rcp *recipe.Definition) (*model.Definition, erro
 	if rcp == nil {
 		return nil, errors.New("definition recipe is nil")
 	}
-	paths, err := ExplorePaths(rcp.Path, rcp.Type, rcp.Format, rcp.RegexPattern)
+	paths, err := ExplorePathsWithOption(rcp.Path, rcp.Type, rcp.Format, rcp.RegexPattern, rcp.Explore)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,546 @@
+package core
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+// nativeObjectName is the name of the object exposing the native functions to every Jsonnet file,
+// which is also importable from the library of the same name
+const nativeObjectName = "valor"
+
+var nativeFunctions = []*jsonnet.NativeFunction{
+	{Name: "regexMatch", Params: ast.Identifiers{"pattern", "str"}, Func: regexMatch},
+	{Name: "regexReplace", Params: ast.Identifiers{"pattern", "str", "replacement"}, Func: regexReplace},
+	{Name: "parseRFC3339", Params: ast.Identifiers{"str"}, Func: parseRFC3339},
+	{Name: "parseCron", Params: ast.Identifiers{"expression"}, Func: parseCron},
+	{Name: "semverCompare", Params: ast.Identifiers{"a", "b"}, Func: semverCompare},
+	{Name: "sha256", Params: ast.Identifiers{"str"}, Func: sha256Hex},
+	{Name: "jsonPath", Params: ast.Identifiers{"value", "path"}, Func: jsonPath},
+	{Name: "cidrContains", Params: ast.Identifiers{"cidr", "ip"}, Func: cidrContains},
+}
+
+// RegisterNativeFunctions registers the Go functions exposed under the valor object into the VM,
+// where each is also callable through std.native with its name prefixed by the object name
+func RegisterNativeFunctions(vm *jsonnet.VM) {
+	for _, fn := range nativeFunctions {
+		vm.NativeFunction(&jsonnet.NativeFunction{
+			Name:   nativeObjectName + "." + fn.Name,
+			Params: fn.Params,
+			Func:   fn.Func,
+		})
+	}
+}
+
+// buildNativeObjectSnippet builds the valor object, where every field refers to a registered native function
+func buildNativeObjectSnippet() string {
+	fields := make([]string, len(nativeFunctions))
+	for i, fn := range nativeFunctions {
+		fields[i] = fmt.Sprintf("%s: std.native(\"%s.%s\")", fn.Name, nativeObjectName, fn.Name)
+	}
+	return fmt.Sprintf("{%s}", strings.Join(fields, ", "))
+}
+
+func getStringArgument(args []interface{}, idx int, name string) (string, error) {
+	output, ok := args[idx].(string)
+	if !ok {
+		return "", fmt.Errorf("argument [%s] should be a string", name)
+	}
+	return output, nil
+}
+
+// regexCacheSize bounds the number of compiled patterns kept, since a pattern could come from any resource
+const regexCacheSize = 256
+
+var patternToRegex = newRegexCache(regexCacheSize)
+
+// regexCache keeps the most recently used compiled patterns, where the least recently used one
+// is evicted once the cache is full
+type regexCache struct {
+	mtx              *sync.Mutex
+	size             int
+	entries          *list.List
+	patternToElement map[string]*list.Element
+}
+
+type regexEntry struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+func newRegexCache(size int) *regexCache {
+	return &regexCache{
+		mtx:              &sync.Mutex{},
+		size:             size,
+		entries:          list.New(),
+		patternToElement: make(map[string]*list.Element),
+	}
+}
+
+func (r *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	r.mtx.Lock()
+	if element, ok := r.patternToElement[pattern]; ok {
+		r.entries.MoveToFront(element)
+		r.mtx.Unlock()
+		return element.Value.(*regexEntry).regex, nil
+	}
+	r.mtx.Unlock()
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.patternToElement[pattern]; !ok {
+		r.patternToElement[pattern] = r.entries.PushFront(&regexEntry{pattern: pattern, regex: regex})
+		if r.entries.Len() > r.size {
+			oldest := r.entries.Back()
+			r.entries.Remove(oldest)
+			delete(r.patternToElement, oldest.Value.(*regexEntry).pattern)
+		}
+	}
+	return regex, nil
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	return patternToRegex.compile(pattern)
+}
+
+func regexMatch(args []interface{}) (interface{}, error) {
+	pattern, err := getStringArgument(args, 0, "pattern")
+	if err != nil {
+		return nil, err
+	}
+	str, err := getStringArgument(args, 1, "str")
+	if err != nil {
+		return nil, err
+	}
+	regex, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return regex.MatchString(str), nil
+}
+
+func regexReplace(args []interface{}) (interface{}, error) {
+	pattern, err := getStringArgument(args, 0, "pattern")
+	if err != nil {
+		return nil, err
+	}
+	str, err := getStringArgument(args, 1, "str")
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := getStringArgument(args, 2, "replacement")
+	if err != nil {
+		return nil, err
+	}
+	regex, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return regex.ReplaceAllString(str, replacement), nil
+}
+
+func parseRFC3339(args []interface{}) (interface{}, error) {
+	str, err := getStringArgument(args, 0, "str")
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return nil, err
+	}
+	_, offset := t.Zone()
+	return map[string]interface{}{
+		"year":       float64(t.Year()),
+		"month":      float64(t.Month()),
+		"day":        float64(t.Day()),
+		"hour":       float64(t.Hour()),
+		"minute":     float64(t.Minute()),
+		"second":     float64(t.Second()),
+		"nanosecond": float64(t.Nanosecond()),
+		"weekday":    t.Weekday().String(),
+		"offset":     float64(offset),
+		"unix":       float64(t.Unix()),
+	}, nil
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	aliases  []string
+}
+
+var cronFields = []*cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "dayOfMonth", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, aliases: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "dayOfWeek", min: 0, max: 7, aliases: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronMacroToExpression = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCron(args []interface{}) (interface{}, error) {
+	expression, err := getStringArgument(args, 0, "expression")
+	if err != nil {
+		return nil, err
+	}
+	if macro, ok := cronMacroToExpression[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = macro
+	}
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron [%s] should have %d fields, but got %d", expression, len(cronFields), len(parts))
+	}
+	output := make(map[string]interface{})
+	for i, field := range cronFields {
+		values, err := parseCronField(parts[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("cron [%s] has invalid %s: %v", expression, field.name, err)
+		}
+		output[field.name] = values
+	}
+	return output, nil
+}
+
+// parseCronField expands a field into its sorted values, where day of week 7 is the same as 0 for Sunday
+func parseCronField(part string, field *cronField) ([]interface{}, error) {
+	selected := make(map[int]bool)
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			rangePart = item[:idx]
+			s, err := strconv.Atoi(item[idx+1:])
+			if err != nil || s <= 0 {
+				return nil, fmt.Errorf("step [%s] should be a positive number", item[idx+1:])
+			}
+			step = s
+		}
+		start, end := field.min, field.max
+		if rangePart != "*" && rangePart != "?" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			start, err = parseCronValue(bounds[0], field)
+			if err != nil {
+				return nil, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = parseCronValue(bounds[1], field)
+				if err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				end = field.max
+			}
+			if start > end {
+				return nil, fmt.Errorf("range [%s] should not be descending", rangePart)
+			}
+		}
+		for v := start; v <= end; v += step {
+			if field.name == "dayOfWeek" && v == 7 {
+				selected[0] = true
+			} else {
+				selected[v] = true
+			}
+		}
+	}
+	var values []int
+	for v := range selected {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	output := make([]interface{}, len(values))
+	for i, v := range values {
+		output[i] = float64(v)
+	}
+	return output, nil
+}
+
+func parseCronValue(value string, field *cronField) (int, error) {
+	for i, alias := range field.aliases {
+		if strings.EqualFold(value, alias) {
+			return field.min + i, nil
+		}
+	}
+	output, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("value [%s] is not a number", value)
+	}
+	if output < field.min || output > field.max {
+		return 0, fmt.Errorf("value [%d] should be between %d and %d", output, field.min, field.max)
+	}
+	return output, nil
+}
+
+type semver struct {
+	numbers    [3]int
+	preRelease []string
+}
+
+func parseSemver(value string) (*semver, error) {
+	version := strings.TrimPrefix(value, "v")
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		version = version[:idx]
+	}
+	output := &semver{}
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		output.preRelease = strings.Split(version[idx+1:], ".")
+		version = version[:idx]
+		for _, identifier := range output.preRelease {
+			if identifier == "" {
+				return nil, fmt.Errorf("version [%s] has an empty pre-release identifier", value)
+			}
+		}
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != len(output.numbers) {
+		return nil, fmt.Errorf("version [%s] should follow major.minor.patch", value)
+	}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("version [%s] has invalid number [%s]", value, part)
+		}
+		output.numbers[i] = number
+	}
+	return output, nil
+}
+
+// compare compares by precedence, where build metadata is ignored
+func (s *semver) compare(other *semver) int {
+	for i := range s.numbers {
+		if s.numbers[i] != other.numbers[i] {
+			return compareInt(s.numbers[i], other.numbers[i])
+		}
+	}
+	// a version without pre-release has higher precedence
+	if len(s.preRelease) == 0 || len(other.preRelease) == 0 {
+		return compareInt(len(other.preRelease), len(s.preRelease))
+	}
+	for i := 0; i < len(s.preRelease) && i < len(other.preRelease); i++ {
+		a, b := s.preRelease[i], other.preRelease[i]
+		if a == b {
+			continue
+		}
+		aNumber, aErr := strconv.Atoi(a)
+		bNumber, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return compareInt(aNumber, bNumber)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	}
+	return compareInt(len(s.preRelease), len(other.preRelease))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func semverCompare(args []interface{}) (interface{}, error) {
+	a, err := getStringArgument(args, 0, "a")
+	if err != nil {
+		return nil, err
+	}
+	b, err := getStringArgument(args, 1, "b")
+	if err != nil {
+		return nil, err
+	}
+	aVersion, err := parseSemver(a)
+	if err != nil {
+		return nil, err
+	}
+	bVersion, err := parseSemver(b)
+	if err != nil {
+		return nil, err
+	}
+	return float64(aVersion.compare(bVersion)), nil
+}
+
+func sha256Hex(args []interface{}) (interface{}, error) {
+	str, err := getStringArgument(args, 0, "str")
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(str))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses the supported subset of JSONPath, which is $, .key, ['key'], [index], .*, and [*]
+func parseJSONPath(path string) ([]*jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path [%s] should start with $", path)
+	}
+	var output []*jsonPathStep
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path [%s] has an empty key", path)
+			}
+			output = append(output, &jsonPathStep{key: key, wildcard: key == "*"})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("path [%s] has an unclosed bracket", path)
+			}
+			content := rest[1:end]
+			step := &jsonPathStep{}
+			if content == "*" {
+				step.wildcard = true
+			} else if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+				step.key = content[1 : len(content)-1]
+			} else {
+				index, err := strconv.Atoi(content)
+				if err != nil {
+					return nil, fmt.Errorf("path [%s] has invalid index [%s]", path, content)
+				}
+				step.index = index
+				step.isIndex = true
+			}
+			output = append(output, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path [%s] has unexpected character [%c]", path, rest[0])
+		}
+	}
+	return output, nil
+}
+
+func jsonPath(args []interface{}) (interface{}, error) {
+	path, err := getStringArgument(args, 1, "path")
+	if err != nil {
+		return nil, err
+	}
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	current := []interface{}{args[0]}
+	hasWildcard := false
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, lookupJSONPathStep(value, step)...)
+		}
+		current = next
+		hasWildcard = hasWildcard || step.wildcard
+	}
+	// a path with wildcard always results in an array, otherwise it results in the value or null if absent
+	if hasWildcard {
+		if current == nil {
+			return []interface{}{}, nil
+		}
+		return current, nil
+	}
+	if len(current) == 0 {
+		return nil, nil
+	}
+	return current[0], nil
+}
+
+func lookupJSONPathStep(value interface{}, step *jsonPathStep) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			output := make([]interface{}, len(keys))
+			for i, key := range keys {
+				output[i] = v[key]
+			}
+			return output
+		}
+		if child, ok := v[step.key]; ok && !step.isIndex {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if step.wildcard {
+			return v
+		}
+		if step.isIndex {
+			index := step.index
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				return []interface{}{v[index]}
+			}
+		}
+	}
+	return nil
+}
+
+func cidrContains(args []interface{}) (interface{}, error) {
+	cidr, err := getStringArgument(args, 0, "cidr")
+	if err != nil {
+		return nil, err
+	}
+	ip, err := getStringArgument(args, 1, "ip")
+	if err != nil {
+		return nil, err
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return nil, fmt.Errorf("ip [%s] is invalid", ip)
+	}
+	return network.Contains(parsedIP), nil
+}
@@ -0,0 +1,233 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+
+	"github.com/google/go-jsonnet"
+	"github.com/stretchr/testify/suite"
+)
+
+type NativeSuite struct {
+	suite.Suite
+}
+
+// evaluateExpression evaluates the expression as the output of a procedure
+func (n *NativeSuite) evaluateExpression(expression string) (string, error) {
+	framework := &model.Framework{
+		Procedures: []*model.Procedure{
+			{
+				Name: "native",
+				Data: &model.Data{
+					Path:    "native.jsonnet",
+					Content: []byte("local valor = import 'valor.libsonnet';\nfunction(resource, definition, previousOutput) " + expression),
+				},
+			},
+		},
+	}
+	importer := core.NewImporter(nil)
+	pool, err := core.NewVMPool(1, func() *jsonnet.VM {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		core.RegisterNativeFunctions(vm)
+		return vm
+	})
+	if err != nil {
+		return "", err
+	}
+	var result string
+	evaluate := func(path, snippet string, arguments map[string]string) (string, error) {
+		output, err := pool.Evaluate(path, snippet, arguments)
+		result = output
+		return output, err
+	}
+	evaluator, err := core.NewEvaluator(framework, evaluate, importer.Add)
+	if err != nil {
+		return "", err
+	}
+	if _, err := evaluator.Evaluate(&model.Data{Path: "resource.json", Content: []byte("{}")}); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func (n *NativeSuite) TestRegexMatch() {
+	n.Run("should return whether the string matches the pattern", func() {
+		actualResult, actualErr := n.evaluateExpression(`[valor.regexMatch("^[a-z]+@gojek\\.com$", s) for s in ["lorem@gojek.com", "lorem@ipsum.com"]]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`[true, false]`, actualResult)
+	})
+
+	n.Run("should return error if pattern is invalid", func() {
+		_, actualErr := n.evaluateExpression(`valor.regexMatch("[a-", "lorem")`)
+
+		n.NotNil(actualErr)
+	})
+
+	n.Run("should keep matching after more distinct patterns than the cache keeps", func() {
+		expression := `local matches = [valor.regexMatch("^" + i + "$", "" + i) for i in std.range(0, 511)];
+			std.length(std.filter(function(m) !m, matches)) == 0 && valor.regexMatch("^0$", "0") && !valor.regexMatch("^1$", "0")`
+
+		actualResult, actualErr := n.evaluateExpression(expression)
+
+		n.Nil(actualErr)
+		n.JSONEq(`true`, actualResult)
+	})
+}
+
+func (n *NativeSuite) TestRegexReplace() {
+	n.Run("should replace every match and expand the groups", func() {
+		actualResult, actualErr := n.evaluateExpression(`valor.regexReplace("(\\w+)@(\\w+)", "lorem@ipsum dolor@sit", "$2.$1")`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`"ipsum.lorem sit.dolor"`, actualResult)
+	})
+
+	n.Run("should return error if argument is not a string", func() {
+		_, actualErr := n.evaluateExpression(`valor.regexReplace("a", 1, "b")`)
+
+		n.NotNil(actualErr)
+	})
+}
+
+func (n *NativeSuite) TestParseRFC3339() {
+	n.Run("should return the parts of the time", func() {
+		actualResult, actualErr := n.evaluateExpression(`valor.parseRFC3339("2021-10-05T13:45:30.5+07:00")`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`{
+			"year": 2021, "month": 10, "day": 5,
+			"hour": 13, "minute": 45, "second": 30, "nanosecond": 500000000,
+			"weekday": "Tuesday", "offset": 25200, "unix": 1633416330
+		}`, actualResult)
+	})
+
+	n.Run("should return error if time is not RFC3339", func() {
+		_, actualErr := n.evaluateExpression(`valor.parseRFC3339("2021-10-05 13:45:30")`)
+
+		n.NotNil(actualErr)
+	})
+}
+
+func (n *NativeSuite) TestParseCron() {
+	n.Run("should expand every field of the expression", func() {
+		actualResult, actualErr := n.evaluateExpression(`valor.parseCron("*/15 9-11 1,15 JAN-MAR MON-FRI")`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`{
+			"minute": [0, 15, 30, 45],
+			"hour": [9, 10, 11],
+			"dayOfMonth": [1, 15],
+			"month": [1, 2, 3],
+			"dayOfWeek": [1, 2, 3, 4, 5]
+		}`, actualResult)
+	})
+
+	n.Run("should expand macro and treat day of week 7 as Sunday", func() {
+		actualResult, actualErr := n.evaluateExpression(`[valor.parseCron("@weekly").dayOfWeek, valor.parseCron("0 0 * * 7").dayOfWeek]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`[[0], [0]]`, actualResult)
+	})
+
+	n.Run("should return error if expression is invalid", func() {
+		for _, expression := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *"} {
+			_, actualErr := n.evaluateExpression(`valor.parseCron("` + expression + `")`)
+
+			n.NotNil(actualErr, expression)
+		}
+	})
+}
+
+func (n *NativeSuite) TestSemverCompare() {
+	n.Run("should compare versions by precedence", func() {
+		actualResult, actualErr := n.evaluateExpression(`[
+			valor.semverCompare("1.2.3", "v1.10.0"),
+			valor.semverCompare("2.0.0", "2.0.0+build.1"),
+			valor.semverCompare("1.0.0", "1.0.0-rc.1"),
+			valor.semverCompare("1.0.0-alpha.2", "1.0.0-alpha.10"),
+			valor.semverCompare("1.0.0-alpha.beta", "1.0.0-alpha.1"),
+			valor.semverCompare("1.0.0-alpha", "1.0.0-alpha.1"),
+		]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`[-1, 0, 1, -1, 1, -1]`, actualResult)
+	})
+
+	n.Run("should return error if version is invalid", func() {
+		_, actualErr := n.evaluateExpression(`valor.semverCompare("1.2", "1.2.0")`)
+
+		n.NotNil(actualErr)
+	})
+}
+
+func (n *NativeSuite) TestSHA256() {
+	n.Run("should return the hex digest", func() {
+		actualResult, actualErr := n.evaluateExpression(`valor.sha256("valor")`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`"6adcc016448d707c1d16827459aee1f5c37621a9a747d3c5feb9f80877e3d495"`, actualResult)
+	})
+}
+
+func (n *NativeSuite) TestJSONPath() {
+	value := `{ users: [{ name: "lorem", roles: ["admin"] }, { name: "ipsum", roles: [] }], "the key": 1 }`
+
+	n.Run("should return the value or null if path is definite", func() {
+		actualResult, actualErr := n.evaluateExpression(`[
+			valor.jsonPath(` + value + `, "$.users[0].name"),
+			valor.jsonPath(` + value + `, "$.users[-1].name"),
+			valor.jsonPath(` + value + `, "$['the key']"),
+			valor.jsonPath(` + value + `, "$.users[5].name"),
+		]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`["lorem", "ipsum", 1, null]`, actualResult)
+	})
+
+	n.Run("should return array of matches if path has wildcard", func() {
+		actualResult, actualErr := n.evaluateExpression(`[
+			valor.jsonPath(` + value + `, "$.users[*].name"),
+			valor.jsonPath(` + value + `, "$.users.*.roles[0]"),
+		]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`[["lorem", "ipsum"], ["admin"]]`, actualResult)
+	})
+
+	n.Run("should return error if path is invalid", func() {
+		for _, path := range []string{"users", "$.users[", "$.users[a]", "$..users"} {
+			_, actualErr := n.evaluateExpression(`valor.jsonPath(` + value + `, "` + path + `")`)
+
+			n.NotNil(actualErr, path)
+		}
+	})
+}
+
+func (n *NativeSuite) TestCIDRContains() {
+	n.Run("should return whether the ip is within the range", func() {
+		actualResult, actualErr := n.evaluateExpression(`[
+			valor.cidrContains("10.0.0.0/8", "10.1.2.3"),
+			valor.cidrContains("10.0.0.0/8", "192.168.1.1"),
+			valor.cidrContains("2001:db8::/32", "2001:db8::1"),
+		]`)
+
+		n.Nil(actualErr)
+		n.JSONEq(`[true, false, true]`, actualResult)
+	})
+
+	n.Run("should return error if cidr or ip is invalid", func() {
+		for _, expression := range []string{`valor.cidrContains("10.0.0.0", "10.0.0.1")`, `valor.cidrContains("10.0.0.0/8", "10.0.0")`} {
+			_, actualErr := n.evaluateExpression(expression)
+
+			n.NotNil(actualErr, expression)
+		}
+	})
+}
+
+func TestNativeSuite(t *testing.T) {
+	suite.Run(t, new(NativeSuite))
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const fileTargetType = "file"
+
+// Plan resolves every resource and framework of the pipeline without evaluating
+// any procedure nor writing any output. Paths of resources and definitions are explored,
+// frameworks are loaded, schemas are compiled, and Jsonnet files are parsed.
+// The output describes which files would be evaluated by which frameworks
+// and where the output would be written.
+func (p *Pipeline) Plan() (*model.Plan, error) {
+	outputError := &model.Error{}
+	nameToFramework := make(map[string]*model.Framework)
+	output := &model.Plan{}
+	for _, resourceRcp := range p.recipe.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		if err := p.validateFrameworkNames(resourceRcp); err != nil {
+			outputError.Add(resourceRcp.Name, err)
+			continue
+		}
+		resourcePaths, err := ExplorePathsWithOption(resourceRcp.Path, resourceRcp.Type, resourceRcp.Format, resourceRcp.RegexPattern, resourceRcp.Explore)
+		if err != nil {
+			outputError.Add(resourceRcp.Name, err)
+			continue
+		}
+		resourcePlan := &model.ResourcePlan{
+			Name:  resourceRcp.Name,
+			Paths: resourcePaths,
+		}
+		for _, frameworkName := range resourceRcp.FrameworkNames {
+			framework, ok := nameToFramework[frameworkName]
+			if !ok {
+				framework, err = p.compileFramework(p.nameToFrameworkRecipe[frameworkName])
+				if err != nil {
+					outputError.Add(frameworkName, err)
+				}
+				nameToFramework[frameworkName] = framework
+			}
+			if framework == nil {
+				continue
+			}
+			resourcePlan.Frameworks = append(resourcePlan.Frameworks, buildFrameworkPlan(framework, resourcePaths))
+		}
+		output.Resources = append(output.Resources, resourcePlan)
+	}
+	if outputError.Length() > 0 {
+		return nil, outputError
+	}
+	return output, nil
+}
+
+func (p *Pipeline) compileFramework(rcp *recipe.Framework) (*model.Framework, error) {
+	framework, err := p.loader.LoadFramework(rcp)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProcedureInputs(framework.Procedures); err != nil {
+		return nil, err
+	}
+	outputError := &model.Error{}
+	for _, schema := range framework.Schemas {
+		if schema == nil || schema.Data == nil {
+			continue
+		}
+		addIssues(outputError, schema.Data.Path, LintCondition(schema.Data.Path, schema.When, schemaConditionVariables))
+		if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema.Data.Content)); err != nil {
+			outputError.Add(schema.Data.Path, err)
+		}
+	}
+	for _, definition := range framework.Definitions {
+		if definition == nil || definition.FunctionData == nil {
+			continue
+		}
+		addIssues(outputError, definition.FunctionData.Path, LintDefinitionFunction(definition.FunctionData))
+	}
+	for _, procedure := range framework.Procedures {
+		if procedure == nil || procedure.Data == nil {
+			continue
+		}
+		issues := append(LintProcedure(procedure), LintCondition(procedure.Data.Path, procedure.When, procedureConditionVariables)...)
+		addIssues(outputError, procedure.Data.Path, issues)
+	}
+	if outputError.Length() > 0 {
+		return nil, outputError
+	}
+	return framework, nil
+}
+
+func addIssues(outputError *model.Error, key string, issues []*model.Issue) {
+	var messages []string
+	for _, issue := range issues {
+		if issue.Severity == model.SeverityError {
+			messages = append(messages, issue.Message)
+		}
+	}
+	if len(messages) > 0 {
+		outputError.Add(key, errors.New(strings.Join(messages, "; ")))
+	}
+}
+
+func buildFrameworkPlan(framework *model.Framework, resourcePaths []string) *model.FrameworkPlan {
+	output := &model.FrameworkPlan{
+		Name: framework.Name,
+	}
+	for _, definition := range framework.Definitions {
+		definitionPlan := &model.DefinitionPlan{
+			Name: definition.Name,
+		}
+		for _, data := range definition.ListOfData {
+			definitionPlan.Paths = append(definitionPlan.Paths, data.Path)
+		}
+		if definition.FunctionData != nil {
+			definitionPlan.FunctionPath = definition.FunctionData.Path
+		}
+		output.Definitions = append(output.Definitions, definitionPlan)
+	}
+	for _, schema := range framework.Schemas {
+		stepPlan := buildStepPlan(schema.Name, schema.Data, schema.Output, resourcePaths)
+		stepPlan.When = schema.When
+		output.Schemas = append(output.Schemas, stepPlan)
+	}
+	for _, procedure := range framework.Procedures {
+		stepPlan := buildStepPlan(procedure.Name, procedure.Data, procedure.Output, resourcePaths)
+		stepPlan.When = procedure.When
+		output.Procedures = append(output.Procedures, stepPlan)
+	}
+	return output
+}
+
+func buildStepPlan(name string, data *model.Data, output *model.Output, resourcePaths []string) *model.StepPlan {
+	stepPlan := &model.StepPlan{
+		Name: name,
+	}
+	if data != nil {
+		stepPlan.Path = data.Path
+	}
+	if output == nil {
+		return stepPlan
+	}
+	stepPlan.TreatAs = string(output.TreatAs)
+	for _, t := range output.Targets {
+		targetPlan := &model.TargetPlan{
+			Name:   t.Name,
+			Type:   t.Type,
+			Format: t.Format,
+		}
+		if t.Type == fileTargetType {
+			// follows how the output is written by treatOutput
+			for _, resourcePath := range resourcePaths {
+				targetPlan.Destinations = append(targetPlan.Destinations, path.Join(t.Path, resourcePath))
+			}
+		}
+		stepPlan.Targets = append(stepPlan.Targets, targetPlan)
+	}
+	return stepPlan
+}
@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	planDirName           = "./plan"
+	planResourceFileName  = "resource.json"
+	planProcedureFileName = "procedure.jsonnet"
+	planInvalidFileName   = "invalid.jsonnet"
+)
+
+type PlannerSuite struct {
+	suite.Suite
+}
+
+func (p *PlannerSuite) SetupSuite() {
+	if err := os.MkdirAll(planDirName, os.ModePerm); err != nil {
+		panic(err)
+	}
+	nameToContent := map[string]string{
+		planResourceFileName:  "{\"message\":0}",
+		planProcedureFileName: "local evaluate(resource, definition, previous) = resource;",
+		planInvalidFileName:   "local evaluate(resource) = resource;",
+	}
+	for name, content := range nameToContent {
+		if err := ioutil.WriteFile(path.Join(planDirName, name), []byte(content), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (p *PlannerSuite) TestPlan() {
+	p.Run("should return plan and nil if recipe is valid", func() {
+		outputDir := path.Join(planDirName, "output")
+		rcp := p.newRecipe(planProcedureFileName, outputDir)
+		pipeline := p.newPipeline(rcp)
+
+		actualPlan, actualErr := pipeline.Plan()
+
+		p.Require().NoError(actualErr)
+		p.Require().Len(actualPlan.Resources, 1)
+		resourcePlan := actualPlan.Resources[0]
+		p.Equal([]string{path.Join(planDirName, planResourceFileName)}, resourcePlan.Paths)
+		p.Require().Len(resourcePlan.Frameworks, 1)
+		procedures := resourcePlan.Frameworks[0].Procedures
+		p.Require().Len(procedures, 1)
+		expectedTargets := []*model.TargetPlan{
+			{
+				Name:         "std_output",
+				Type:         "std",
+				Format:       "json",
+				Destinations: nil,
+			},
+			{
+				Name:         "file_output",
+				Type:         "file",
+				Format:       "json",
+				Destinations: []string{path.Join(outputDir, planDirName, planResourceFileName)},
+			},
+		}
+		p.Equal(expectedTargets, procedures[0].Targets)
+		p.NoDirExists(outputDir)
+	})
+
+	p.Run("should return nil and error if procedure is invalid", func() {
+		rcp := p.newRecipe(planInvalidFileName, planDirName)
+		pipeline := p.newPipeline(rcp)
+
+		actualPlan, actualErr := pipeline.Plan()
+
+		p.Nil(actualPlan)
+		p.NotNil(actualErr)
+	})
+
+	p.Run("should return nil and error if framework is not found", func() {
+		rcp := p.newRecipe(planProcedureFileName, planDirName)
+		rcp.Resources[0].FrameworkNames = []string{"unknown"}
+		pipeline := p.newPipeline(rcp)
+
+		actualPlan, actualErr := pipeline.Plan()
+
+		p.Nil(actualPlan)
+		p.NotNil(actualErr)
+	})
+}
+
+func (p *PlannerSuite) newRecipe(procedureFileName, outputDir string) *recipe.Recipe {
+	return &recipe.Recipe{
+		Resources: []*recipe.Resource{
+			{
+				Name:           "test_resource",
+				Type:           defaultValidType,
+				Path:           path.Join(planDirName, planResourceFileName),
+				Format:         "json",
+				FrameworkNames: []string{"test_framework"},
+			},
+		},
+		Frameworks: []*recipe.Framework{
+			{
+				Name: "test_framework",
+				Procedures: []*recipe.Procedure{
+					{
+						Name: "test_procedure",
+						Type: defaultValidType,
+						Path: path.Join(planDirName, procedureFileName),
+						Output: &recipe.Output{
+							TreatAs: "success",
+							Targets: []*recipe.Target{
+								{Name: "std_output", Type: "std", Format: "json"},
+								{Name: "file_output", Type: "file", Format: "json", Path: outputDir},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *PlannerSuite) newPipeline(rcp *recipe.Recipe) *core.Pipeline {
+	evaluate := func(path, snippet string, arguments map[string]string) (string, error) {
+		p.FailNow("evaluate should not be called")
+		return "", nil
+	}
+	newProgress := func(name string, total int) model.Progress {
+		return nil
+	}
+	pipeline, err := core.NewPipeline(rcp, evaluate, ignoreFile, newProgress)
+	p.Require().NoError(err)
+	return pipeline
+}
+
+func (p *PlannerSuite) TearDownSuite() {
+	if err := os.RemoveAll(planDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestPlannerSuite(t *testing.T) {
+	suite.Run(t, new(PlannerSuite))
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+// resetArgumentCode is bound to every argument once an evaluation is done
+const resetArgumentCode = "null"
+
+// nodeCacheSize bounds the number of parsed snippets kept, since a snippet could come from any recipe step
+const nodeCacheSize = 256
+
+// NewVM creates a Jsonnet VM, where every VM created by it should be configured identically
+type NewVM func() *jsonnet.VM
+
+// VMPool hands each evaluation its own Jsonnet VM, since a VM cannot be used concurrently.
+// It keeps the most recently used parsed snippets, where the least recently used one
+// is evicted once the cache is full.
+type VMPool struct {
+	vms chan *jsonnet.VM
+
+	mtx          *sync.Mutex
+	entries      *list.List
+	keyToElement map[snippetKey]*list.Element
+}
+
+type snippetKey struct {
+	path    string
+	snippet string
+}
+
+type nodeEntry struct {
+	key  snippetKey
+	node ast.Node
+}
+
+// NewVMPool initializes VMPool with the number of VMs specified by size,
+// where size zero means one VM for every CPU
+func NewVMPool(size int, newVM NewVM) (*VMPool, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("pool size [%d] should not be negative", size)
+	}
+	if newVM == nil {
+		return nil, errors.New("new VM function is nil")
+	}
+	if size == 0 {
+		size = runtime.NumCPU()
+	}
+	vms := make(chan *jsonnet.VM, size)
+	for i := 0; i < size; i++ {
+		vm := newVM()
+		if vm == nil {
+			return nil, errors.New("new VM function returns nil")
+		}
+		vms <- vm
+	}
+	return &VMPool{
+		vms:          vms,
+		mtx:          &sync.Mutex{},
+		entries:      list.New(),
+		keyToElement: make(map[snippetKey]*list.Element),
+	}, nil
+}
+
+// Evaluate evaluates snippet on a VM taken from the pool, which waits until one is available.
+// Import within the snippet is relative to the path. The snippet is parsed once for the same path
+// and content, then reused by the later evaluations. Arguments are bound as top-level arguments,
+// since binding an external variable flushes the values cached by the VM, such as imported libraries.
+// A top-level argument cannot be unbound, so it is reset once the evaluation is done, which means
+// every snippet evaluated through the pool should accept the arguments of the other snippets.
+func (p *VMPool) Evaluate(path, snippet string, arguments map[string]string) (string, error) {
+	vm := <-p.vms
+	defer func() {
+		for name := range arguments {
+			vm.TLACode(name, resetArgumentCode)
+		}
+		p.vms <- vm
+	}()
+	for name, code := range arguments {
+		vm.TLACode(name, code)
+	}
+	node, err := p.parse(path, snippet)
+	if err != nil {
+		return "", errors.New(vm.ErrorFormatter.Format(err))
+	}
+	output, err := vm.Evaluate(node)
+	if err != nil {
+		return "", errors.New(vm.ErrorFormatter.Format(err))
+	}
+	return output, nil
+}
+
+func (p *VMPool) parse(path, snippet string) (ast.Node, error) {
+	key := snippetKey{
+		path:    path,
+		snippet: snippet,
+	}
+	p.mtx.Lock()
+	if element, ok := p.keyToElement[key]; ok {
+		p.entries.MoveToFront(element)
+		p.mtx.Unlock()
+		return element.Value.(*nodeEntry).node, nil
+	}
+	p.mtx.Unlock()
+
+	node, err := jsonnet.SnippetToAST(path, snippet)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if _, ok := p.keyToElement[key]; !ok {
+		p.keyToElement[key] = p.entries.PushFront(&nodeEntry{key: key, node: node})
+		if p.entries.Len() > nodeCacheSize {
+			oldest := p.entries.Back()
+			p.entries.Remove(oldest)
+			delete(p.keyToElement, oldest.Value.(*nodeEntry).key)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,296 @@
+package core_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/google/go-jsonnet"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	poolDirName           = "./pool"
+	poolResourceDirName   = "resource"
+	poolOutputDirName     = "output"
+	poolNumberOfResources = 64
+)
+
+type noopProgress struct{}
+
+func (noopProgress) Increase(int) {}
+
+func (noopProgress) Wait() {}
+
+type VMPoolSuite struct {
+	suite.Suite
+}
+
+func (v *VMPoolSuite) SetupSuite() {
+	if err := os.MkdirAll(path.Join(poolDirName, poolResourceDirName), os.ModePerm); err != nil {
+		panic(err)
+	}
+	if err := os.MkdirAll(path.Join(poolDirName, "lib"), os.ModePerm); err != nil {
+		panic(err)
+	}
+	nameToContent := map[string]string{
+		"lib/math.libsonnet":  "{ multiply(a, b): a * b }",
+		"lib/count.libsonnet": "std.native('count')()",
+		"factor.jsonnet":      "local construct(definition) = { value: definition[0].value };",
+		"multiply.jsonnet":    "local math = import 'lib/math.libsonnet';\nfunction(resource, definition, previousOutput) { result: math.multiply(resource.n, definition.factor.value) }",
+		"factor.json":         "{\"value\": 3}",
+		"unknown_var.jsonnet": "local evaluate(resource, definition, previousOutput) = unknown;",
+	}
+	for i := 0; i < poolNumberOfResources; i++ {
+		nameToContent[path.Join(poolResourceDirName, fmt.Sprintf("%d.json", i))] = fmt.Sprintf("{\"n\": %d}", i)
+	}
+	for name, content := range nameToContent {
+		if err := ioutil.WriteFile(path.Join(poolDirName, name), []byte(content), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (v *VMPoolSuite) TestNewVMPool() {
+	v.Run("should return nil and error if size is negative", func() {
+		actualPool, actualErr := core.NewVMPool(-1, jsonnet.MakeVM)
+
+		v.Nil(actualPool)
+		v.NotNil(actualErr)
+	})
+
+	v.Run("should return nil and error if new VM function is nil", func() {
+		actualPool, actualErr := core.NewVMPool(1, nil)
+
+		v.Nil(actualPool)
+		v.NotNil(actualErr)
+	})
+
+	v.Run("should return nil and error if new VM function returns nil", func() {
+		newVM := func() *jsonnet.VM {
+			return nil
+		}
+
+		actualPool, actualErr := core.NewVMPool(1, newVM)
+
+		v.Nil(actualPool)
+		v.NotNil(actualErr)
+	})
+
+	v.Run("should create one VM for every CPU if size is zero", func() {
+		var counter int
+		newVM := func() *jsonnet.VM {
+			counter++
+			return jsonnet.MakeVM()
+		}
+
+		actualPool, actualErr := core.NewVMPool(0, newVM)
+
+		v.NotNil(actualPool)
+		v.Nil(actualErr)
+		v.Positive(counter)
+	})
+}
+
+func (v *VMPoolSuite) TestEvaluate() {
+	v.Run("should return error if snippet is invalid", func() {
+		pool, _ := core.NewVMPool(1, jsonnet.MakeVM)
+
+		actualResult, actualErr := pool.Evaluate("invalid.jsonnet", "{", nil)
+
+		v.Empty(actualResult)
+		v.NotNil(actualErr)
+	})
+
+	v.Run("should bind arguments and resolve import relative to the path", func() {
+		pool, _ := core.NewVMPool(1, jsonnet.MakeVM)
+		snippetPath := path.Join(poolDirName, "snippet.jsonnet")
+		snippet := "local math = import 'lib/math.libsonnet'; function(a, b) math.multiply(a, b)"
+
+		expectedResult := "6\n"
+
+		actualResult, actualErr := pool.Evaluate(snippetPath, snippet, map[string]string{"a": "2", "b": "3"})
+
+		v.Equal(expectedResult, actualResult)
+		v.Nil(actualErr)
+	})
+
+	v.Run("should bind arguments of each evaluation to the snippet parsed before", func() {
+		pool, _ := core.NewVMPool(1, jsonnet.MakeVM)
+		pool.Evaluate("snippet.jsonnet", "function(a) a * 2", map[string]string{"a": "1"})
+
+		expectedResult := "6\n"
+
+		actualResult, actualErr := pool.Evaluate("snippet.jsonnet", "function(a) a * 2", map[string]string{"a": "3"})
+
+		v.Equal(expectedResult, actualResult)
+		v.Nil(actualErr)
+	})
+
+	v.Run("should not be affected by arguments of the previous evaluation", func() {
+		pool, _ := core.NewVMPool(1, jsonnet.MakeVM)
+		pool.Evaluate("first.jsonnet", "function(a=null, b=null) a", map[string]string{"a": "1", "b": "2"})
+
+		expectedResult := "[\n   3,\n   null\n]\n"
+
+		actualResult, actualErr := pool.Evaluate("second.jsonnet", "function(a=null, b=null) [a, b]", map[string]string{"a": "3"})
+
+		v.Equal(expectedResult, actualResult)
+		v.Nil(actualErr)
+	})
+
+	v.Run("should keep the library imported by the previous evaluation", func() {
+		var counter int
+		newVM := func() *jsonnet.VM {
+			vm := jsonnet.MakeVM()
+			vm.NativeFunction(&jsonnet.NativeFunction{
+				Name: "count",
+				Func: func([]interface{}) (interface{}, error) {
+					counter++
+					return float64(counter), nil
+				},
+			})
+			return vm
+		}
+		pool, _ := core.NewVMPool(1, newVM)
+		snippetPath := path.Join(poolDirName, "snippet.jsonnet")
+		snippet := "local count = import 'lib/count.libsonnet'; function(a) a + count"
+		pool.Evaluate(snippetPath, snippet, map[string]string{"a": "1"})
+
+		expectedResult := "4\n"
+		expectedCounter := 1
+
+		actualResult, actualErr := pool.Evaluate(snippetPath, snippet, map[string]string{"a": "3"})
+
+		v.Equal(expectedResult, actualResult)
+		v.Nil(actualErr)
+		v.Equal(expectedCounter, counter)
+	})
+
+	v.Run("should evaluate every snippet beyond the number of parsed snippets kept", func() {
+		pool, _ := core.NewVMPool(1, jsonnet.MakeVM)
+		var actualErr error
+		for i := 0; i < 512; i++ {
+			snippet := fmt.Sprintf("function(a) a + %d", i)
+			if _, err := pool.Evaluate("snippet.jsonnet", snippet, map[string]string{"a": "1"}); err != nil {
+				actualErr = err
+			}
+		}
+
+		expectedResult := "2\n"
+
+		actualResult, err := pool.Evaluate("snippet.jsonnet", "function(a) a + 1", map[string]string{"a": "1"})
+
+		v.Nil(actualErr)
+		v.Nil(err)
+		v.Equal(expectedResult, actualResult)
+	})
+}
+
+// TestExecuteConcurrently is meant to be run with the race detector, where every
+// resource is executed concurrently while sharing fewer VMs than the batch size
+func (v *VMPoolSuite) TestExecuteConcurrently() {
+	v.Run("should evaluate every resource with its own result", func() {
+		outputDir := path.Join(poolDirName, poolOutputDirName)
+		rcp := v.newRecipe("multiply.jsonnet", outputDir)
+		pipeline := v.newPipeline(rcp)
+
+		actualErr := pipeline.Execute()
+
+		v.Nil(actualErr)
+		for i := 0; i < poolNumberOfResources; i++ {
+			resourcePath := path.Join(poolDirName, poolResourceDirName, fmt.Sprintf("%d.json", i))
+			content, err := ioutil.ReadFile(path.Join(outputDir, resourcePath))
+			v.Require().NoError(err)
+			v.JSONEq(fmt.Sprintf("{\"result\": %d}", i*3), string(content))
+		}
+	})
+
+	v.Run("should return error for every resource if procedure is invalid", func() {
+		rcp := v.newRecipe("unknown_var.jsonnet", path.Join(poolDirName, poolOutputDirName))
+		pipeline := v.newPipeline(rcp)
+
+		actualErr := pipeline.Execute()
+
+		v.Require().IsType(&model.Error{}, actualErr)
+		v.Equal(poolNumberOfResources, actualErr.(*model.Error).Length())
+	})
+}
+
+func (v *VMPoolSuite) newRecipe(procedureFileName, outputDir string) *recipe.Recipe {
+	return &recipe.Recipe{
+		Resources: []*recipe.Resource{
+			{
+				Name:           "test_resource",
+				Type:           "dir",
+				Path:           path.Join(poolDirName, poolResourceDirName),
+				Format:         "json",
+				BatchSize:      poolNumberOfResources,
+				FrameworkNames: []string{"test_framework"},
+			},
+		},
+		Frameworks: []*recipe.Framework{
+			{
+				Name: "test_framework",
+				Definitions: []*recipe.Definition{
+					{
+						Name:   "factor",
+						Format: "json",
+						Type:   "file",
+						Path:   path.Join(poolDirName, "factor.json"),
+						Function: &recipe.Function{
+							Type: "file",
+							Path: path.Join(poolDirName, "factor.jsonnet"),
+						},
+					},
+				},
+				Procedures: []*recipe.Procedure{
+					{
+						Name: "test_procedure",
+						Type: "file",
+						Path: path.Join(poolDirName, procedureFileName),
+						When: "resource.n >= 0",
+						Output: &recipe.Output{
+							TreatAs: "success",
+							Targets: []*recipe.Target{
+								{Name: "file_output", Type: "file", Format: "json", Path: outputDir},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (v *VMPoolSuite) newPipeline(rcp *recipe.Recipe) *core.Pipeline {
+	importer := core.NewImporter(nil)
+	pool, err := core.NewVMPool(4, func() *jsonnet.VM {
+		vm := jsonnet.MakeVM()
+		vm.Importer(importer)
+		return vm
+	})
+	v.Require().NoError(err)
+	newProgress := func(name string, total int) model.Progress {
+		return noopProgress{}
+	}
+	pipeline, err := core.NewPipeline(rcp, pool.Evaluate, importer.Add, newProgress)
+	v.Require().NoError(err)
+	return pipeline
+}
+
+func (v *VMPoolSuite) TearDownSuite() {
+	if err := os.RemoveAll(poolDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestVMPoolSuite(t *testing.T) {
+	suite.Run(t, new(VMPoolSuite))
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+)
+
+// Profile profiles a recipe by resolving the files of its resources and definitions.
+// Nothing is read nor evaluated, so the recipe is expected to have its extends
+// and output references resolved beforehand.
+func Profile(rcp *recipe.Recipe) (*model.Profile, error) {
+	if rcp == nil {
+		return nil, errors.New("recipe is nil")
+	}
+	outputError := &model.Error{}
+	output := &model.Profile{}
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		paths, err := ExplorePathsWithOption(resourceRcp.Path, resourceRcp.Type, resourceRcp.Format, resourceRcp.RegexPattern, resourceRcp.Explore)
+		if err != nil {
+			outputError.Add(resourceRcp.Name, err)
+			continue
+		}
+		output.Resources = append(output.Resources, &model.ResourceProfile{
+			Name:           resourceRcp.Name,
+			Format:         resourceRcp.Format,
+			Type:           resourceRcp.Type,
+			Path:           resourceRcp.Path,
+			BatchSize:      resourceRcp.BatchSize,
+			Tags:           resourceRcp.Tags,
+			FrameworkNames: resourceRcp.FrameworkNames,
+			FileCount:      len(paths),
+		})
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		frameworkProfile, err := profileFramework(frameworkRcp)
+		if err != nil {
+			outputError.Add(frameworkRcp.Name, err)
+			continue
+		}
+		output.Frameworks = append(output.Frameworks, frameworkProfile)
+	}
+	for _, d := range rcp.Defaulted {
+		output.Defaulted = append(output.Defaulted, &model.DefaultedProfile{
+			Key:   d.Key,
+			Value: d.Value,
+		})
+	}
+	if outputError.Length() > 0 {
+		return nil, outputError
+	}
+	return output, nil
+}
+
+func profileFramework(rcp *recipe.Framework) (*model.FrameworkProfile, error) {
+	outputError := &model.Error{}
+	output := &model.FrameworkProfile{
+		Name: rcp.Name,
+		Tags: rcp.Tags,
+	}
+	for _, definitionRcp := range rcp.Definitions {
+		if definitionRcp == nil {
+			continue
+		}
+		paths, err := ExplorePathsWithOption(definitionRcp.Path, definitionRcp.Type, definitionRcp.Format, definitionRcp.RegexPattern, definitionRcp.Explore)
+		if err != nil {
+			outputError.Add(definitionRcp.Name, err)
+			continue
+		}
+		definitionProfile := &model.DefinitionProfile{
+			Name:   definitionRcp.Name,
+			Format: definitionRcp.Format,
+			Type:   definitionRcp.Type,
+			Path:   definitionRcp.Path,
+			Files:  paths,
+		}
+		if definitionRcp.Function != nil {
+			definitionProfile.FunctionPath = definitionRcp.Function.Path
+		}
+		output.Definitions = append(output.Definitions, definitionProfile)
+	}
+	for i, schemaRcp := range rcp.Schemas {
+		if schemaRcp == nil {
+			continue
+		}
+		stepProfile := buildStepProfile(i+1, schemaRcp.Name, schemaRcp.Type, schemaRcp.Path, schemaRcp.Output)
+		stepProfile.When = schemaRcp.When
+		output.Schemas = append(output.Schemas, stepProfile)
+	}
+	for i, procedureRcp := range rcp.Procedures {
+		if procedureRcp == nil {
+			continue
+		}
+		stepProfile := buildStepProfile(i+1, procedureRcp.Name, procedureRcp.Type, procedureRcp.Path, procedureRcp.Output)
+		stepProfile.Inputs = procedureRcp.Inputs
+		stepProfile.When = procedureRcp.When
+		output.Procedures = append(output.Procedures, stepProfile)
+	}
+	if outputError.Length() > 0 {
+		return nil, outputError
+	}
+	return output, nil
+}
+
+func buildStepProfile(order int, name, _type, path string, output *recipe.Output) *model.StepProfile {
+	stepProfile := &model.StepProfile{
+		Order: order,
+		Name:  name,
+		Type:  _type,
+		Path:  path,
+	}
+	if output == nil {
+		return stepProfile
+	}
+	stepProfile.TreatAs = output.TreatAs
+	for _, t := range output.Targets {
+		if t == nil {
+			continue
+		}
+		stepProfile.Targets = append(stepProfile.Targets, &model.TargetProfile{
+			Name:   t.Name,
+			Type:   t.Type,
+			Format: t.Format,
+			Path:   t.Path,
+		})
+	}
+	return stepProfile
+}
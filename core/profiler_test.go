@@ -0,0 +1,141 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/core"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const profileDirName = "./profile"
+
+type ProfilerSuite struct {
+	suite.Suite
+}
+
+func (p *ProfilerSuite) SetupSuite() {
+	if err := os.MkdirAll(profileDirName, os.ModePerm); err != nil {
+		panic(err)
+	}
+	for _, name := range []string{"first.json", "second.json"} {
+		if err := ioutil.WriteFile(path.Join(profileDirName, name), []byte("{}"), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (p *ProfilerSuite) TestProfile() {
+	p.Run("should return nil and error if recipe is nil", func() {
+		var rcp *recipe.Recipe = nil
+
+		actualProfile, actualErr := core.Profile(rcp)
+
+		p.Nil(actualProfile)
+		p.NotNil(actualErr)
+	})
+
+	p.Run("should return nil and error if path cannot be explored", func() {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:   "test_resource",
+					Type:   "dir",
+					Path:   path.Join(profileDirName, "unknown"),
+					Format: "json",
+				},
+			},
+		}
+
+		actualProfile, actualErr := core.Profile(rcp)
+
+		p.Nil(actualProfile)
+		p.NotNil(actualErr)
+	})
+
+	p.Run("should return profile with resolved files and steps in order", func() {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "test_resource",
+					Type:           "dir",
+					Path:           profileDirName,
+					Format:         "json",
+					FrameworkNames: []string{"test_framework"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "test_framework",
+					Definitions: []*recipe.Definition{
+						{
+							Name:   "test_definition",
+							Type:   "dir",
+							Path:   profileDirName,
+							Format: "json",
+						},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "first", Type: "file", Path: "first.jsonnet"},
+						{
+							Name: "second",
+							Type: "file",
+							Path: "second.jsonnet",
+							Output: &recipe.Output{
+								TreatAs: "success",
+								Targets: []*recipe.Target{
+									{Name: "file_output", Type: "file", Format: "json", Path: "./out"},
+								},
+							},
+						},
+					},
+				},
+			},
+			Defaulted: []*recipe.DefaultedField{
+				{Key: "resources[test_resource].format", Value: "json"},
+			},
+		}
+		expectedFiles := []string{
+			path.Join(profileDirName, "first.json"),
+			path.Join(profileDirName, "second.json"),
+		}
+		expectedProcedures := []*model.StepProfile{
+			{Order: 1, Name: "first", Type: "file", Path: "first.jsonnet"},
+			{
+				Order:   2,
+				Name:    "second",
+				Type:    "file",
+				Path:    "second.jsonnet",
+				TreatAs: "success",
+				Targets: []*model.TargetProfile{
+					{Name: "file_output", Type: "file", Format: "json", Path: "./out"},
+				},
+			},
+		}
+
+		actualProfile, actualErr := core.Profile(rcp)
+
+		p.Require().NoError(actualErr)
+		p.Require().Len(actualProfile.Resources, 1)
+		p.Equal(2, actualProfile.Resources[0].FileCount)
+		p.Require().Len(actualProfile.Frameworks, 1)
+		p.Equal(expectedFiles, actualProfile.Frameworks[0].Definitions[0].Files)
+		p.Equal(expectedProcedures, actualProfile.Frameworks[0].Procedures)
+		p.Len(actualProfile.Defaulted, 1)
+	})
+}
+
+func (p *ProfilerSuite) TearDownSuite() {
+	if err := os.RemoveAll(profileDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestProfilerSuite(t *testing.T) {
+	suite.Run(t, new(ProfilerSuite))
+}
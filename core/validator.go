@@ -11,14 +11,19 @@ import (
 
 // Validator is a validator for Resource against a Schema
 type Validator struct {
+	evaluate  model.Evaluate
 	framework *model.Framework
+	skipped   *skipRecorder
 }
 
 // NewValidator initializes Validator
-func NewValidator(framework *model.Framework) (*Validator, error) {
+func NewValidator(framework *model.Framework, evaluate model.Evaluate) (*Validator, error) {
 	if framework == nil {
 		return nil, errors.New("framework is nil")
 	}
+	if evaluate == nil {
+		return nil, errors.New("evaluate function is nil")
+	}
 	outputError := &model.Error{}
 	for i, sch := range framework.Schemas {
 		if sch == nil {
@@ -30,10 +35,17 @@ func NewValidator(framework *model.Framework) (*Validator, error) {
 		return nil, outputError
 	}
 	return &Validator{
+		evaluate:  evaluate,
 		framework: framework,
+		skipped:   newSkipRecorder(),
 	}, nil
 }
 
+// Skipped returns the schemas skipped by their when condition along with the skipped resource paths
+func (v *Validator) Skipped() []*model.SkippedStep {
+	return v.skipped.list()
+}
+
 // Validate validates a Resource data against all schemas
 func (v *Validator) Validate(resourceData *model.Data) (bool, error) {
 	if resourceData == nil {
@@ -43,6 +55,17 @@ func (v *Validator) Validate(resourceData *model.Data) (bool, error) {
 		if schema.Data == nil {
 			return false, fmt.Errorf("schema data for [%s] is nil", schema.Name)
 		}
+		conditionSnippet := buildConditionSnippet(schema.When)
+		applied, err := evaluateCondition(v.evaluate, schema.Name, conditionSnippet, map[string]string{
+			resourceArgument: string(resourceData.Content),
+		})
+		if err != nil {
+			return false, err
+		}
+		if !applied {
+			v.skipped.record(schemaStepType, schema.Name, resourceData.Path)
+			continue
+		}
 		schemaLoader := gojsonschema.NewStringLoader(string(schema.Data.Content))
 		recordLoader := gojsonschema.NewStringLoader(string(resourceData.Content))
 		result, validateErr := gojsonschema.Validate(schemaLoader, recordLoader)
@@ -6,10 +6,19 @@ import (
 	"github.com/gojek/optimus-extension-valor/core"
 	"github.com/gojek/optimus-extension-valor/model"
 
+	"github.com/google/go-jsonnet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
+func evaluateJsonnet(path, snippet string, arguments map[string]string) (string, error) {
+	vm := jsonnet.MakeVM()
+	for name, code := range arguments {
+		vm.TLACode(name, code)
+	}
+	return vm.EvaluateAnonymousSnippet(path, snippet)
+}
+
 type ValidatorSuite struct {
 	suite.Suite
 }
@@ -18,7 +27,7 @@ func (v *ValidatorSuite) TestValidate() {
 	v.Run("should return false and error if resource data is nil", func() {
 		framework := &model.Framework{}
 		var resourceData *model.Data = nil
-		validator, _ := core.NewValidator(framework)
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
 
 		actualSuccess, actualErr := validator.Validate(resourceData)
 
@@ -36,7 +45,7 @@ func (v *ValidatorSuite) TestValidate() {
 			},
 		}
 		resourceData := &model.Data{}
-		validator, _ := core.NewValidator(framework)
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
 
 		actualSuccess, actualErr := validator.Validate(resourceData)
 
@@ -89,7 +98,7 @@ func (v *ValidatorSuite) TestValidate() {
 		resourceData := &model.Data{
 			Content: []byte(resourceContent),
 		}
-		validator, _ := core.NewValidator(framework)
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
 
 		actualSuccess, actualErr := validator.Validate(resourceData)
 
@@ -142,12 +151,64 @@ func (v *ValidatorSuite) TestValidate() {
 		resourceData := &model.Data{
 			Content: []byte(resourceContent),
 		}
-		validator, _ := core.NewValidator(framework)
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
+
+		actualSuccess, actualErr := validator.Validate(resourceData)
+
+		v.True(actualSuccess)
+		v.Nil(actualErr)
+	})
+
+	v.Run("should skip schema and record it if when condition is false", func() {
+		framework := &model.Framework{
+			Schemas: []*model.Schema{
+				{
+					Name: "schema_test",
+					When: "resource.is_active",
+					Data: &model.Data{
+						Content: []byte(`{"required": ["email"]}`),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Path:    "resource.json",
+			Content: []byte(`{"is_active": false}`),
+		}
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
+
+		expectedSkipped := []*model.SkippedStep{
+			{Type: "schema", Name: "schema_test", Paths: []string{"resource.json"}},
+		}
 
 		actualSuccess, actualErr := validator.Validate(resourceData)
 
 		v.True(actualSuccess)
 		v.Nil(actualErr)
+		v.Equal(expectedSkipped, validator.Skipped())
+	})
+
+	v.Run("should return false and error if when condition is not a boolean", func() {
+		framework := &model.Framework{
+			Schemas: []*model.Schema{
+				{
+					Name: "schema_test",
+					When: "resource.email",
+					Data: &model.Data{
+						Content: []byte(`{}`),
+					},
+				},
+			},
+		}
+		resourceData := &model.Data{
+			Content: []byte(`{"email": "valor@gojek.com"}`),
+		}
+		validator, _ := core.NewValidator(framework, evaluateJsonnet)
+
+		actualSuccess, actualErr := validator.Validate(resourceData)
+
+		v.False(actualSuccess)
+		v.NotNil(actualErr)
 	})
 }
 
@@ -159,7 +220,17 @@ func TestNewValidator(t *testing.T) {
 	t.Run("should return nil and error if framework is nil", func(t *testing.T) {
 		var framework *model.Framework = nil
 
-		actualValue, actualErr := core.NewValidator(framework)
+		actualValue, actualErr := core.NewValidator(framework, evaluateJsonnet)
+
+		assert.Nil(t, actualValue)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if evaluate is nil", func(t *testing.T) {
+		framework := &model.Framework{}
+		var evaluate model.Evaluate = nil
+
+		actualValue, actualErr := core.NewValidator(framework, evaluate)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -175,7 +246,7 @@ func TestNewValidator(t *testing.T) {
 			},
 		}
 
-		actualValue, actualErr := core.NewValidator(framework)
+		actualValue, actualErr := core.NewValidator(framework, evaluateJsonnet)
 
 		assert.Nil(t, actualValue)
 		assert.NotNil(t, actualErr)
@@ -190,7 +261,7 @@ func TestNewValidator(t *testing.T) {
 			},
 		}
 
-		actualValue, actualErr := core.NewValidator(framework)
+		actualValue, actualErr := core.NewValidator(framework, evaluateJsonnet)
 
 		assert.NotNil(t, actualValue)
 		assert.Nil(t, actualErr)
@@ -60,6 +60,7 @@ type Definition struct {
 type Schema struct {
 	Name   string
 	Data   *Data
+	When   string
 	Output *Output
 }
 
@@ -67,9 +68,18 @@ type Schema struct {
 type Procedure struct {
 	Name   string
 	Data   *Data
+	Inputs []string
+	When   string
 	Output *Output
 }
 
+// SkippedStep describes a schema or a procedure skipped by its when condition
+type SkippedStep struct {
+	Type  string
+	Name  string
+	Paths []string
+}
+
 // Output describes how the last procedure output is written
 type Output struct {
 	TreatAs OutputTreatment
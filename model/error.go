@@ -10,18 +10,15 @@ import (
 type Error struct {
 	keyToValue map[string]interface{}
 
-	initialized bool
-	mtx         *sync.Mutex
+	mtx sync.Mutex
 }
 
-// Add adds a new error based on a specified key
+// Add adds a new error based on a specified key, which is safe to be called concurrently
 func (e *Error) Add(key string, value interface{}) {
-	if !e.initialized {
+	e.mtx.Lock()
+	if e.keyToValue == nil {
 		e.keyToValue = make(map[string]interface{})
-		e.mtx = &sync.Mutex{}
-		e.initialized = true
 	}
-	e.mtx.Lock()
 	e.keyToValue[key] = value
 	e.mtx.Unlock()
 }
@@ -53,8 +50,16 @@ func (e *Error) JSON() []byte {
 	return output
 }
 
+// Map returns the map representation of the errors, where the key is
+// the error key and the value is either the error message or a nested map
+func (e *Error) Map() map[string]interface{} {
+	return e.buildMap()
+}
+
 // Length returns the number of errors stored so far
 func (e *Error) Length() int {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
 	return len(e.keyToValue)
 }
 
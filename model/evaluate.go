@@ -1,4 +1,11 @@
 package model
 
-// Evaluate evaluates snippet
-type Evaluate func(name, snippet string) (string, error)
+// Evaluate evaluates snippet, where path is used to resolve its imports and to report its errors.
+// Each argument is keyed by its name and written as Jsonnet code, where the snippet results in
+// a function that receives the arguments as its top-level arguments. The same path and snippet
+// are evaluated for every resource, so the parsed snippet could be reused.
+type Evaluate func(path, snippet string, arguments map[string]string) (string, error)
+
+// AddFile adds a file to be imported from the path by the snippets passed to Evaluate,
+// where the content of a file should stay the same once it is added
+type AddFile func(path, content string) error
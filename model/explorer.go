@@ -1,4 +1,26 @@
 package model
 
+const (
+	// SymlinkSkip skips symbolic link during exploration
+	SymlinkSkip SymlinkPolicy = "skip"
+	// SymlinkFollow follows symbolic link during exploration
+	SymlinkFollow SymlinkPolicy = "follow"
+	// SymlinkError returns error when symbolic link is encountered
+	SymlinkError SymlinkPolicy = "error"
+)
+
+// SymlinkPolicy is a policy on how symbolic link is treated
+type SymlinkPolicy string
+
 // ExplorePath explores path from its root with filter
 type ExplorePath func(root string, filter func(string) bool) ([]string, error)
+
+// NewExplorePath initializes ExplorePath based on the option
+type NewExplorePath func(option *ExploreOption) ExplorePath
+
+// ExploreOption defines how a path should be explored
+type ExploreOption struct {
+	Recursive bool
+	MaxDepth  int
+	Symlink   SymlinkPolicy
+}
@@ -0,0 +1,21 @@
+package model
+
+const (
+	// SeverityError is severity for an issue that has to be fixed
+	SeverityError IssueSeverity = "error"
+	// SeverityWarning is severity for an issue that should be reviewed
+	SeverityWarning IssueSeverity = "warning"
+)
+
+// IssueSeverity is a severity of an issue
+type IssueSeverity string
+
+// Issue describes a problem found without executing the recipe
+type Issue struct {
+	Rule     string        `json:"rule"`
+	Severity IssueSeverity `json:"severity"`
+	Path     string        `json:"path"`
+	Line     int           `json:"line,omitempty"`
+	Column   int           `json:"column,omitempty"`
+	Message  string        `json:"message"`
+}
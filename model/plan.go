@@ -0,0 +1,45 @@
+package model
+
+// Plan describes what a pipeline would do without executing it
+type Plan struct {
+	Resources []*ResourcePlan `json:"resources" yaml:"resources"`
+}
+
+// ResourcePlan describes the files of a resource and the frameworks evaluating them
+type ResourcePlan struct {
+	Name       string           `json:"name" yaml:"name"`
+	Paths      []string         `json:"paths" yaml:"paths"`
+	Frameworks []*FrameworkPlan `json:"frameworks" yaml:"frameworks"`
+}
+
+// FrameworkPlan describes the steps of a framework for a resource
+type FrameworkPlan struct {
+	Name        string            `json:"name" yaml:"name"`
+	Definitions []*DefinitionPlan `json:"definitions" yaml:"definitions"`
+	Schemas     []*StepPlan       `json:"schemas" yaml:"schemas"`
+	Procedures  []*StepPlan       `json:"procedures" yaml:"procedures"`
+}
+
+// DefinitionPlan describes the files of a definition
+type DefinitionPlan struct {
+	Name         string   `json:"name" yaml:"name"`
+	Paths        []string `json:"paths" yaml:"paths"`
+	FunctionPath string   `json:"function_path,omitempty" yaml:"function_path,omitempty"`
+}
+
+// StepPlan describes a schema or a procedure along with where its output is written
+type StepPlan struct {
+	Name    string        `json:"name" yaml:"name"`
+	Path    string        `json:"path" yaml:"path"`
+	When    string        `json:"when,omitempty" yaml:"when,omitempty"`
+	TreatAs string        `json:"treat_as,omitempty" yaml:"treat_as,omitempty"`
+	Targets []*TargetPlan `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// TargetPlan describes where an output would be written
+type TargetPlan struct {
+	Name         string   `json:"name" yaml:"name"`
+	Type         string   `json:"type" yaml:"type"`
+	Format       string   `json:"format" yaml:"format"`
+	Destinations []string `json:"destinations" yaml:"destinations"`
+}
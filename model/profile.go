@@ -0,0 +1,65 @@
+package model
+
+// Profile describes a recipe along with the files resolved from it
+type Profile struct {
+	Resources  []*ResourceProfile  `json:"resources" yaml:"resources"`
+	Frameworks []*FrameworkProfile `json:"frameworks" yaml:"frameworks"`
+	Defaulted  []*DefaultedProfile `json:"defaulted,omitempty" yaml:"defaulted,omitempty"`
+}
+
+// ResourceProfile describes a resource and the number of files resolved from its path
+type ResourceProfile struct {
+	Name           string   `json:"name" yaml:"name"`
+	Format         string   `json:"format" yaml:"format"`
+	Type           string   `json:"type" yaml:"type"`
+	Path           string   `json:"path" yaml:"path"`
+	BatchSize      int      `json:"batch_size" yaml:"batch_size"`
+	Tags           []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	FrameworkNames []string `json:"framework_names" yaml:"framework_names"`
+	FileCount      int      `json:"file_count" yaml:"file_count"`
+}
+
+// FrameworkProfile describes a framework with its steps in the order they are executed
+type FrameworkProfile struct {
+	Name        string               `json:"name" yaml:"name"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Definitions []*DefinitionProfile `json:"definitions" yaml:"definitions"`
+	Schemas     []*StepProfile       `json:"schemas" yaml:"schemas"`
+	Procedures  []*StepProfile       `json:"procedures" yaml:"procedures"`
+}
+
+// DefinitionProfile describes a definition and the files resolved from its path
+type DefinitionProfile struct {
+	Name         string   `json:"name" yaml:"name"`
+	Format       string   `json:"format" yaml:"format"`
+	Type         string   `json:"type" yaml:"type"`
+	Path         string   `json:"path" yaml:"path"`
+	Files        []string `json:"files" yaml:"files"`
+	FunctionPath string   `json:"function_path,omitempty" yaml:"function_path,omitempty"`
+}
+
+// StepProfile describes a schema or a procedure, where order starts from one
+type StepProfile struct {
+	Order   int              `json:"order" yaml:"order"`
+	Name    string           `json:"name" yaml:"name"`
+	Type    string           `json:"type" yaml:"type"`
+	Path    string           `json:"path" yaml:"path"`
+	Inputs  []string         `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	When    string           `json:"when,omitempty" yaml:"when,omitempty"`
+	TreatAs string           `json:"treat_as,omitempty" yaml:"treat_as,omitempty"`
+	Targets []*TargetProfile `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// TargetProfile describes an output target
+type TargetProfile struct {
+	Name   string `json:"name" yaml:"name"`
+	Type   string `json:"type" yaml:"type"`
+	Format string `json:"format" yaml:"format"`
+	Path   string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// DefaultedProfile describes a field supplied by the recipe defaults
+type DefaultedProfile struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
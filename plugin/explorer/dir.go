@@ -0,0 +1,121 @@
+package explorer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/registry/explorer"
+)
+
+const dirType = "dir"
+
+// Dir explores a directory based on its option
+type Dir struct {
+	option *model.ExploreOption
+}
+
+// Explore explores directory path from its root based on its filter
+func (d *Dir) Explore(root string, filter func(string) bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if filter == nil || filter(root) {
+			return []string{root}, nil
+		}
+		return nil, nil
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	visited := map[string]bool{
+		realRoot: true,
+	}
+	return d.explore(root, 1, filter, visited)
+}
+
+func (d *Dir) explore(dirPath string, depth int, filter func(string) bool, visited map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	var output []string
+	for _, entry := range entries {
+		path := filepath.Join(dirPath, entry.Name())
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			switch d.option.Symlink {
+			case model.SymlinkFollow:
+				info, err := os.Stat(path)
+				if err != nil {
+					// dangling symbolic link has nothing to be read, so it is skipped
+					continue
+				}
+				isDir = info.IsDir()
+			case model.SymlinkError:
+				return nil, fmt.Errorf("symbolic link [%s] is not allowed", path)
+			default:
+				continue
+			}
+		}
+		if isDir {
+			if !d.shouldDescend(depth) {
+				continue
+			}
+			// directory reached both directly and through symbolic link is only explored once
+			realPath, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return nil, err
+			}
+			if visited[realPath] {
+				continue
+			}
+			visited[realPath] = true
+			paths, err := d.explore(path, depth+1, filter, visited)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, paths...)
+			continue
+		}
+		if filter == nil || filter(path) {
+			output = append(output, path)
+		}
+	}
+	sort.Strings(output)
+	return output, nil
+}
+
+func (d *Dir) shouldDescend(depth int) bool {
+	if !d.option.Recursive {
+		return false
+	}
+	return d.option.MaxDepth <= 0 || depth < d.option.MaxDepth
+}
+
+// NewDir initializes Dir explorer based on the option
+func NewDir(option *model.ExploreOption) *Dir {
+	opt := model.ExploreOption{}
+	if option != nil {
+		opt = *option
+	}
+	if opt.Symlink == "" {
+		opt.Symlink = model.SymlinkSkip
+	}
+	return &Dir{
+		option: &opt,
+	}
+}
+
+func init() {
+	if err := explorer.Explorers.RegisterWithOption(dirType, func(option *model.ExploreOption) model.ExplorePath {
+		return NewDir(option).Explore
+	}); err != nil {
+		panic(err)
+	}
+}
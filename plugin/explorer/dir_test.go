@@ -0,0 +1,169 @@
+package explorer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/explorer"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const defaultDirName = "./out"
+
+type DirSuite struct {
+	suite.Suite
+}
+
+func (d *DirSuite) SetupSuite() {
+	files := []string{
+		"root.json",
+		"child/child.json",
+		"child/grandchild/grandchild.json",
+	}
+	for _, f := range files {
+		filePath := path.Join(defaultDirName, f)
+		dirPath, _ := path.Split(filePath)
+		if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte("{}"), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+	if err := os.Symlink("child", path.Join(defaultDirName, "link")); err != nil {
+		panic(err)
+	}
+}
+
+func (d *DirSuite) TestExplore() {
+	d.Run("should return error if root does not exist", func() {
+		dir := explorer.NewDir(nil)
+
+		actualPaths, actualErr := dir.Explore(path.Join(defaultDirName, "unknown"), nil)
+
+		d.Nil(actualPaths)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return root if root is a file", func() {
+		dir := explorer.NewDir(nil)
+		root := path.Join(defaultDirName, "root.json")
+
+		actualPaths, actualErr := dir.Explore(root, nil)
+
+		d.Equal([]string{root}, actualPaths)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return direct files only if not recursive", func() {
+		dir := explorer.NewDir(nil)
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Len(actualPaths, 1)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return all files if recursive", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Len(actualPaths, 3)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return files up to max depth if recursive with max depth", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+			MaxDepth:  2,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Len(actualPaths, 2)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return files through symbolic link if symlink is followed", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+			Symlink:   model.SymlinkFollow,
+		})
+
+		actualPaths, actualErr := dir.Explore(path.Join(defaultDirName, "link"), nil)
+
+		d.Len(actualPaths, 2)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return files of directory reached directly and through symbolic link only once", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+			Symlink:   model.SymlinkFollow,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Len(actualPaths, 3)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should skip dangling symbolic link if symlink is followed", func() {
+		dangling := path.Join(defaultDirName, "child", "dangling")
+		if err := os.Symlink("unknown", dangling); err != nil {
+			panic(err)
+		}
+		defer os.Remove(dangling)
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+			Symlink:   model.SymlinkFollow,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Len(actualPaths, 3)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should return error if symbolic link is encountered and policy is error", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Symlink: model.SymlinkError,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, nil)
+
+		d.Nil(actualPaths)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return filtered files if filter is set", func() {
+		dir := explorer.NewDir(&model.ExploreOption{
+			Recursive: true,
+		})
+
+		actualPaths, actualErr := dir.Explore(defaultDirName, func(p string) bool {
+			return strings.HasSuffix(p, "child.json")
+		})
+
+		d.Len(actualPaths, 2)
+		d.Nil(actualErr)
+	})
+}
+
+func (d *DirSuite) TearDownSuite() {
+	if err := os.RemoveAll(defaultDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestDirSuite(t *testing.T) {
+	suite.Run(t, &DirSuite{})
+}
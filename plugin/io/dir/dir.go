@@ -0,0 +1,118 @@
+package dir
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	registry "github.com/gojek/optimus-extension-valor/registry/io"
+)
+
+const _type = "dir"
+
+// Dir represents directory operation
+type Dir struct {
+	getPath     model.GetPath
+	postProcess model.PostProcess
+
+	paths       []string
+	initialized bool
+}
+
+// Read reads the file pointed by path. If the path is a directory,
+// then each call yields the next file directly under that directory
+// and returns io.EOF once all files are read.
+func (d *Dir) Read() (*model.Data, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	if !d.initialized {
+		paths, err := d.listPaths(d.getPath())
+		if err != nil {
+			return nil, err
+		}
+		d.paths = paths
+		d.initialized = true
+	}
+	if len(d.paths) == 0 {
+		return nil, io.EOF
+	}
+	path := d.paths[0]
+	d.paths = d.paths[1:]
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.postProcess(path, content)
+}
+
+// ReadAll reads all files pointed by path
+func (d *Dir) ReadAll() ([]*model.Data, error) {
+	var output []*model.Data
+	for {
+		data, err := d.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, data)
+	}
+	return output, nil
+}
+
+func (d *Dir) listPaths(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var output []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		output = append(output, filepath.Join(path, entry.Name()))
+	}
+	sort.Strings(output)
+	return output, nil
+}
+
+func (d *Dir) validate() error {
+	if d.getPath == nil {
+		return errors.New("getPath is nil")
+	}
+	if d.postProcess == nil {
+		return errors.New("postProcess is nil")
+	}
+	return nil
+}
+
+// New initializes Dir based on path
+func New(getPath model.GetPath, postProcess model.PostProcess) *Dir {
+	return &Dir{
+		getPath:     getPath,
+		postProcess: postProcess,
+	}
+}
+
+func init() {
+	if err := registry.Readers.Register(_type,
+		func(getPath model.GetPath, postProcess model.PostProcess) model.Reader {
+			return New(getPath, postProcess)
+		},
+	); err != nil {
+		panic(err)
+	}
+}
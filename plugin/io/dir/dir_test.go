@@ -0,0 +1,156 @@
+package dir_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/io/dir"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	defaultDirName     = "./out"
+	defaultSubDirName  = "sub"
+	defaultFileName1   = "test1.yaml"
+	defaultFileName2   = "test2.yaml"
+	defaultFileContent = "message"
+)
+
+type DirSuite struct {
+	suite.Suite
+}
+
+func (d *DirSuite) SetupSuite() {
+	if err := os.MkdirAll(path.Join(defaultDirName, defaultSubDirName), os.ModePerm); err != nil {
+		panic(err)
+	}
+	for _, name := range []string{defaultFileName1, defaultFileName2} {
+		filePath := path.Join(defaultDirName, name)
+		if err := ioutil.WriteFile(filePath, []byte(defaultFileContent), os.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (d *DirSuite) TestRead() {
+	var postProcess model.PostProcess = func(path string, content []byte) (*model.Data, error) {
+		return &model.Data{
+			Content: content,
+			Path:    path,
+		}, nil
+	}
+
+	d.Run("should return error if getPath nil", func() {
+		var getPath model.GetPath = nil
+		reader := dir.New(getPath, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		d.Nil(actualData)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return error if postProcess nil", func() {
+		var getPath model.GetPath = func() string {
+			return defaultDirName
+		}
+		reader := dir.New(getPath, nil)
+
+		actualData, actualErr := reader.Read()
+
+		d.Nil(actualData)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return error if path does not exist", func() {
+		var getPath model.GetPath = func() string {
+			return path.Join(defaultDirName, "unknown")
+		}
+		reader := dir.New(getPath, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		d.Nil(actualData)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return error if error is found when post process", func() {
+		var getPath model.GetPath = func() string {
+			return defaultDirName
+		}
+		reader := dir.New(getPath, func(path string, content []byte) (*model.Data, error) {
+			return nil, errors.New("test error")
+		})
+
+		actualData, actualErr := reader.Read()
+
+		d.Nil(actualData)
+		d.NotNil(actualErr)
+	})
+
+	d.Run("should return the file if path is a file", func() {
+		filePath := path.Join(defaultDirName, defaultFileName1)
+		var getPath model.GetPath = func() string {
+			return filePath
+		}
+		reader := dir.New(getPath, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		d.Equal(filePath, actualData.Path)
+		d.Nil(actualErr)
+	})
+
+	d.Run("should yield each file and return EOF once all files are read", func() {
+		var getPath model.GetPath = func() string {
+			return defaultDirName
+		}
+		reader := dir.New(getPath, postProcess)
+
+		firstData, firstErr := reader.Read()
+		secondData, secondErr := reader.Read()
+		thirdData, thirdErr := reader.Read()
+
+		d.Equal(path.Join(defaultDirName, defaultFileName1), firstData.Path)
+		d.Nil(firstErr)
+		d.Equal(path.Join(defaultDirName, defaultFileName2), secondData.Path)
+		d.Nil(secondErr)
+		d.Nil(thirdData)
+		d.Equal(io.EOF, thirdErr)
+	})
+}
+
+func (d *DirSuite) TestReadAll() {
+	d.Run("should return all files directly under directory", func() {
+		var getPath model.GetPath = func() string {
+			return defaultDirName
+		}
+		reader := dir.New(getPath, func(path string, content []byte) (*model.Data, error) {
+			return &model.Data{
+				Content: content,
+				Path:    path,
+			}, nil
+		})
+
+		actualData, actualErr := reader.ReadAll()
+
+		d.Len(actualData, 2)
+		d.Nil(actualErr)
+	})
+}
+
+func (d *DirSuite) TearDownSuite() {
+	if err := os.RemoveAll(defaultDirName); err != nil {
+		panic(err)
+	}
+}
+
+func TestDirSuite(t *testing.T) {
+	suite.Run(t, &DirSuite{})
+}
@@ -46,9 +46,11 @@ func (f *File) Write(data *model.Data) error {
 	if data == nil {
 		return errors.New("data is nil")
 	}
-	dirPath, _ := path.Split(data.Path)
-	if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
-		return err
+	// a path without directory is written to the working directory as is
+	if dirPath, _ := path.Split(data.Path); dirPath != "" {
+		if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+			return err
+		}
 	}
 	return ioutil.WriteFile(data.Path, data.Content, os.ModePerm)
 }
@@ -120,6 +120,21 @@ func (f *FileSuite) TestWrite() {
 
 		f.Nil(actualErr)
 	})
+
+	f.Run("should write to working directory if path has no directory", func() {
+		defer func() { os.Remove(defaultFileName) }()
+		data := &model.Data{
+			Type:    "file",
+			Path:    defaultFileName,
+			Content: []byte(defaultContent),
+		}
+		reader := file.New(nil, nil)
+
+		actualErr := reader.Write(data)
+
+		f.Nil(actualErr)
+		f.FileExists(defaultFileName)
+	})
 }
 
 func (f *FileSuite) TearDownSuite() {
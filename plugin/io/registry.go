@@ -1,6 +1,8 @@
 package io
 
 import (
-	_ "github.com/gojek/optimus-extension-valor/plugin/io/file" // init File io
-	_ "github.com/gojek/optimus-extension-valor/plugin/io/std"  // init Std io
+	_ "github.com/gojek/optimus-extension-valor/plugin/io/dir"   // init Dir io
+	_ "github.com/gojek/optimus-extension-valor/plugin/io/file"  // init File io
+	_ "github.com/gojek/optimus-extension-valor/plugin/io/std"   // init Std io
+	_ "github.com/gojek/optimus-extension-valor/plugin/io/stdin" // init Stdin io
 )
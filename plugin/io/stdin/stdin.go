@@ -0,0 +1,64 @@
+package stdin
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	registry "github.com/gojek/optimus-extension-valor/registry/io"
+)
+
+const _type = "stdin"
+
+// Stdin is a reader for standard input
+type Stdin struct {
+	source      io.Reader
+	getPath     model.GetPath
+	postProcess model.PostProcess
+}
+
+// Read reads the whole content of the source until EOF
+func (s *Stdin) Read() (*model.Data, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(s.source)
+	if err != nil {
+		return nil, err
+	}
+	return s.postProcess(s.getPath(), content)
+}
+
+func (s *Stdin) validate() error {
+	if s.source == nil {
+		return errors.New("source is nil")
+	}
+	if s.getPath == nil {
+		return errors.New("getPath is nil")
+	}
+	if s.postProcess == nil {
+		return errors.New("postProcess is nil")
+	}
+	return nil
+}
+
+// New initializes Stdin to read from the source
+func New(source io.Reader, getPath model.GetPath, postProcess model.PostProcess) *Stdin {
+	return &Stdin{
+		source:      source,
+		getPath:     getPath,
+		postProcess: postProcess,
+	}
+}
+
+func init() {
+	if err := registry.Readers.Register(_type,
+		func(getPath model.GetPath, postProcess model.PostProcess) model.Reader {
+			return New(os.Stdin, getPath, postProcess)
+		},
+	); err != nil {
+		panic(err)
+	}
+}
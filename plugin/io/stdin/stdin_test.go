@@ -0,0 +1,91 @@
+package stdin_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/io/stdin"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	defaultPath    = "-"
+	defaultContent = "message"
+)
+
+type StdinSuite struct {
+	suite.Suite
+}
+
+func (s *StdinSuite) TestRead() {
+	getPath := func() string {
+		return defaultPath
+	}
+	postProcess := func(path string, content []byte) (*model.Data, error) {
+		return &model.Data{
+			Content: content,
+			Path:    path,
+		}, nil
+	}
+
+	s.Run("should return error if source is nil", func() {
+		reader := stdin.New(nil, getPath, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		s.Nil(actualData)
+		s.NotNil(actualErr)
+	})
+
+	s.Run("should return error if getPath is nil", func() {
+		reader := stdin.New(strings.NewReader(defaultContent), nil, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		s.Nil(actualData)
+		s.NotNil(actualErr)
+	})
+
+	s.Run("should return error if postProcess is nil", func() {
+		reader := stdin.New(strings.NewReader(defaultContent), getPath, nil)
+
+		actualData, actualErr := reader.Read()
+
+		s.Nil(actualData)
+		s.NotNil(actualErr)
+	})
+
+	s.Run("should return error if postProcess returns error", func() {
+		postProcessErr := errors.New("post process error")
+		postProcess := func(path string, content []byte) (*model.Data, error) {
+			return nil, postProcessErr
+		}
+		reader := stdin.New(strings.NewReader(defaultContent), getPath, postProcess)
+
+		actualData, actualErr := reader.Read()
+
+		s.Nil(actualData)
+		s.EqualValues(postProcessErr, actualErr)
+	})
+
+	s.Run("should return the whole content and nil if no error is encountered", func() {
+		reader := stdin.New(strings.NewReader(defaultContent), getPath, postProcess)
+
+		expectedData := &model.Data{
+			Content: []byte(defaultContent),
+			Path:    defaultPath,
+		}
+
+		actualData, actualErr := reader.Read()
+
+		s.EqualValues(expectedData, actualData)
+		s.Nil(actualErr)
+	})
+}
+
+func TestStdinSuite(t *testing.T) {
+	suite.Run(t, &StdinSuite{})
+}
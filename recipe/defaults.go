@@ -0,0 +1,195 @@
+package recipe
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ApplyDefaults applies the defaults of a recipe into its resources and frameworks.
+// A field is only supplied by defaults if it is not set. Output defaults are applied
+// after the schema and procedure defaults, so they also apply to the output supplied by them,
+// and to the shared outputs defined in the same recipe.
+// Every field supplied by defaults is recorded in Defaulted.
+func ApplyDefaults(rcp *Recipe) {
+	if rcp == nil || rcp.Defaults == nil {
+		return
+	}
+	defaults := rcp.Defaults
+	var outputNames []string
+	for name := range rcp.Outputs {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+	for _, name := range outputNames {
+		prefix := fmt.Sprintf("outputs[%s]", name)
+		rcp.Defaulted = append(rcp.Defaulted, applyOutputDefaults(prefix, rcp.Outputs[name], defaults.Output)...)
+	}
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("resources[%s]", resourceRcp.Name)
+		rcp.Defaulted = append(rcp.Defaulted, applyResourceDefaults(prefix, resourceRcp, defaults.Resource)...)
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("frameworks[%s]", frameworkRcp.Name)
+		for _, schemaRcp := range frameworkRcp.Schemas {
+			if schemaRcp == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s.schemas[%s]", prefix, schemaRcp.Name)
+			rcp.Defaulted = append(rcp.Defaulted, applySchemaDefaults(key, schemaRcp, defaults.Schema)...)
+			rcp.Defaulted = append(rcp.Defaulted, applyOutputDefaults(key+".output", schemaRcp.Output, defaults.Output)...)
+		}
+		for _, definitionRcp := range frameworkRcp.Definitions {
+			if definitionRcp == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s.definitions[%s]", prefix, definitionRcp.Name)
+			rcp.Defaulted = append(rcp.Defaulted, applyDefinitionDefaults(key, definitionRcp, defaults.Definition)...)
+		}
+		for _, procedureRcp := range frameworkRcp.Procedures {
+			if procedureRcp == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s.procedures[%s]", prefix, procedureRcp.Name)
+			rcp.Defaulted = append(rcp.Defaulted, applyProcedureDefaults(key, procedureRcp, defaults.Procedure)...)
+			rcp.Defaulted = append(rcp.Defaulted, applyOutputDefaults(key+".output", procedureRcp.Output, defaults.Output)...)
+		}
+	}
+}
+
+func applyResourceDefaults(prefix string, rcp *Resource, defaults *ResourceDefaults) []*DefaultedField {
+	if defaults == nil {
+		return nil
+	}
+	var output []*DefaultedField
+	applyString(prefix+".format", &rcp.Format, defaults.Format, &output)
+	applyString(prefix+".type", &rcp.Type, defaults.Type, &output)
+	applyString(prefix+".regex_pattern", &rcp.RegexPattern, defaults.RegexPattern, &output)
+	if rcp.BatchSize == 0 && defaults.BatchSize != 0 {
+		rcp.BatchSize = defaults.BatchSize
+		output = append(output, newDefaultedField(prefix+".batch_size", defaults.BatchSize))
+	}
+	if rcp.Explore == nil && defaults.Explore != nil {
+		explore := *defaults.Explore
+		rcp.Explore = &explore
+		output = append(output, newDefaultedField(prefix+".explore", explore))
+	}
+	applyStrings(prefix+".framework_names", &rcp.FrameworkNames, defaults.FrameworkNames, &output)
+	applyStrings(prefix+".tags", &rcp.Tags, defaults.Tags, &output)
+	return output
+}
+
+func applyDefinitionDefaults(prefix string, rcp *Definition, defaults *DefinitionDefaults) []*DefaultedField {
+	if defaults == nil {
+		return nil
+	}
+	var output []*DefaultedField
+	applyString(prefix+".format", &rcp.Format, defaults.Format, &output)
+	applyString(prefix+".type", &rcp.Type, defaults.Type, &output)
+	applyString(prefix+".regex_pattern", &rcp.RegexPattern, defaults.RegexPattern, &output)
+	if rcp.Explore == nil && defaults.Explore != nil {
+		explore := *defaults.Explore
+		rcp.Explore = &explore
+		output = append(output, newDefaultedField(prefix+".explore", explore))
+	}
+	return output
+}
+
+func applySchemaDefaults(prefix string, rcp *Schema, defaults *SchemaDefaults) []*DefaultedField {
+	if defaults == nil {
+		return nil
+	}
+	var output []*DefaultedField
+	applyString(prefix+".type", &rcp.Type, defaults.Type, &output)
+	if rcp.Output == nil && rcp.OutputRef == "" && defaults.Output != nil {
+		rcp.Output = copyOutput(defaults.Output)
+		output = append(output, newDefaultedField(prefix+".output", rcp.Output))
+	}
+	return output
+}
+
+func applyProcedureDefaults(prefix string, rcp *Procedure, defaults *ProcedureDefaults) []*DefaultedField {
+	if defaults == nil {
+		return nil
+	}
+	var output []*DefaultedField
+	applyString(prefix+".type", &rcp.Type, defaults.Type, &output)
+	if rcp.Output == nil && rcp.OutputRef == "" && defaults.Output != nil {
+		rcp.Output = copyOutput(defaults.Output)
+		output = append(output, newDefaultedField(prefix+".output", rcp.Output))
+	}
+	return output
+}
+
+func applyOutputDefaults(prefix string, rcp *Output, defaults *OutputDefaults) []*DefaultedField {
+	if rcp == nil || defaults == nil {
+		return nil
+	}
+	var output []*DefaultedField
+	applyString(prefix+".treat_as", &rcp.TreatAs, defaults.TreatAs, &output)
+	if len(rcp.Targets) == 0 && len(defaults.Targets) > 0 {
+		rcp.Targets = copyTargets(defaults.Targets)
+		var names []string
+		for _, t := range rcp.Targets {
+			names = append(names, t.Name)
+		}
+		output = append(output, newDefaultedField(prefix+".targets", names))
+	}
+	return output
+}
+
+func applyString(key string, target *string, value string, output *[]*DefaultedField) {
+	if *target == "" && value != "" {
+		*target = value
+		*output = append(*output, newDefaultedField(key, value))
+	}
+}
+
+func applyStrings(key string, target *[]string, value []string, output *[]*DefaultedField) {
+	if len(*target) == 0 && len(value) > 0 {
+		*target = append([]string{}, value...)
+		*output = append(*output, newDefaultedField(key, value))
+	}
+}
+
+func copyOutput(rcp *Output) *Output {
+	return &Output{
+		TreatAs: rcp.TreatAs,
+		Targets: copyTargets(rcp.Targets),
+	}
+}
+
+func copyTargets(rcps []*Target) []*Target {
+	var output []*Target
+	for _, t := range rcps {
+		if t == nil {
+			continue
+		}
+		target := *t
+		output = append(output, &target)
+	}
+	return output
+}
+
+// newDefaultedField records the value the way it is written in a recipe, where a string
+// is kept as it is and any other value is encoded as JSON
+func newDefaultedField(key string, value interface{}) *DefaultedField {
+	text, ok := value.(string)
+	if !ok {
+		content, err := json.Marshal(value)
+		if err != nil {
+			content = []byte(fmt.Sprint(value))
+		}
+		text = string(content)
+	}
+	return &DefaultedField{
+		Key:   key,
+		Value: text,
+	}
+}
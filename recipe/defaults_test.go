@@ -0,0 +1,139 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	t.Run("should not change recipe if defaults is nil", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{Name: "resource"},
+			},
+		}
+
+		recipe.ApplyDefaults(rcp)
+
+		assert.Empty(t, rcp.Resources[0].Format)
+		assert.Empty(t, rcp.Defaulted)
+	})
+
+	t.Run("should only supply fields that are not set and record them", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Defaults: &recipe.Defaults{
+				Resource: &recipe.ResourceDefaults{
+					Format:         "json",
+					Type:           "dir",
+					BatchSize:      2,
+					FrameworkNames: []string{"framework"},
+				},
+			},
+			Resources: []*recipe.Resource{
+				{
+					Name:   "resource",
+					Format: "yaml",
+				},
+			},
+		}
+
+		expectedDefaulted := []*recipe.DefaultedField{
+			{Key: "resources[resource].type", Value: "dir"},
+			{Key: "resources[resource].batch_size", Value: "2"},
+			{Key: "resources[resource].framework_names", Value: `["framework"]`},
+		}
+
+		recipe.ApplyDefaults(rcp)
+
+		assert.Equal(t, "yaml", rcp.Resources[0].Format)
+		assert.Equal(t, "dir", rcp.Resources[0].Type)
+		assert.Equal(t, 2, rcp.Resources[0].BatchSize)
+		assert.Equal(t, []string{"framework"}, rcp.Resources[0].FrameworkNames)
+		assert.EqualValues(t, expectedDefaulted, rcp.Defaulted)
+	})
+
+	t.Run("should record structured value encoded as JSON", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Defaults: &recipe.Defaults{
+				Definition: &recipe.DefinitionDefaults{
+					Explore: &recipe.Explore{Recursive: true, Symlink: "follow"},
+				},
+				Schema: &recipe.SchemaDefaults{
+					Output: &recipe.Output{
+						TreatAs: "error",
+						Targets: []*recipe.Target{
+							{Name: "std_output", Type: "std", Format: "yaml"},
+						},
+					},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "framework",
+					Schemas: []*recipe.Schema{
+						{Name: "rule"},
+					},
+					Definitions: []*recipe.Definition{
+						{Name: "memberships"},
+					},
+				},
+			},
+		}
+
+		expectedDefaulted := []*recipe.DefaultedField{
+			{
+				Key:   "frameworks[framework].schemas[rule].output",
+				Value: `{"treat_as":"error","targets":[{"name":"std_output","format":"yaml","type":"std","path":""}]}`,
+			},
+			{
+				Key:   "frameworks[framework].definitions[memberships].explore",
+				Value: `{"recursive":true,"max_depth":0,"symlink":"follow"}`,
+			},
+		}
+
+		recipe.ApplyDefaults(rcp)
+
+		assert.EqualValues(t, expectedDefaulted, rcp.Defaulted)
+	})
+
+	t.Run("should apply output defaults after procedure defaults without sharing targets", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Defaults: &recipe.Defaults{
+				Procedure: &recipe.ProcedureDefaults{
+					Type: "file",
+					Output: &recipe.Output{
+						Targets: []*recipe.Target{
+							{Name: "std_output", Type: "std", Format: "yaml"},
+						},
+					},
+				},
+				Output: &recipe.OutputDefaults{
+					TreatAs: "success",
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "framework",
+					Procedures: []*recipe.Procedure{
+						{Name: "first"},
+						{Name: "second"},
+					},
+				},
+			},
+		}
+
+		recipe.ApplyDefaults(rcp)
+
+		first := rcp.Frameworks[0].Procedures[0]
+		second := rcp.Frameworks[0].Procedures[1]
+		assert.Equal(t, "file", first.Type)
+		assert.Equal(t, "success", first.Output.TreatAs)
+		assert.Len(t, first.Output.Targets, 1)
+		assert.NotSame(t, first.Output.Targets[0], second.Output.Targets[0])
+		assert.NotSame(t, rcp.Defaults.Procedure.Output, first.Output)
+		assert.Empty(t, rcp.Defaults.Procedure.Output.TreatAs)
+	})
+}
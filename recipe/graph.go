@@ -0,0 +1,198 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// GraphNodeResource is the kind of node for a resource
+	GraphNodeResource = "resource"
+	// GraphNodeFramework is the kind of node for a framework
+	GraphNodeFramework = "framework"
+	// GraphNodeDefinition is the kind of node for a definition
+	GraphNodeDefinition = "definition"
+	// GraphNodeSchema is the kind of node for a schema
+	GraphNodeSchema = "schema"
+	// GraphNodeProcedure is the kind of node for a procedure
+	GraphNodeProcedure = "procedure"
+	// GraphNodeTarget is the kind of node for an output target
+	GraphNodeTarget = "target"
+)
+
+var graphNodeKindToDOTShape = map[string]string{
+	GraphNodeResource:   "folder",
+	GraphNodeFramework:  "box3d",
+	GraphNodeDefinition: "note",
+	GraphNodeSchema:     "component",
+	GraphNodeProcedure:  "box",
+	GraphNodeTarget:     "cylinder",
+}
+
+// GraphNode is a node of the recipe graph
+type GraphNode struct {
+	ID    string
+	Kind  string
+	Label string
+}
+
+// GraphEdge is a directed edge of the recipe graph
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// Graph describes how resources flow into frameworks, their steps, and their output targets
+type Graph struct {
+	Nodes []*GraphNode
+	Edges []*GraphEdge
+
+	idToNode map[string]*GraphNode
+}
+
+// BuildGraph builds the graph of resource -> framework -> definition, schema,
+// or procedure -> output target from a recipe. A procedure is also connected
+// from every procedure listed under its inputs. Output references are expected
+// to be resolved beforehand, so every step carries its own output.
+func BuildGraph(rcp *Recipe) (*Graph, error) {
+	if rcp == nil {
+		return nil, errors.New("recipe is nil")
+	}
+	output := &Graph{
+		idToNode: make(map[string]*GraphNode),
+	}
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		resourceID := output.addNode(GraphNodeResource, resourceRcp.Name, resourceRcp.Name)
+		for _, frameworkName := range resourceRcp.FrameworkNames {
+			frameworkID := output.addNode(GraphNodeFramework, frameworkName, frameworkName)
+			output.addEdge(resourceID, frameworkID)
+		}
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		frameworkID := output.addNode(GraphNodeFramework, frameworkRcp.Name, frameworkRcp.Name)
+		for _, definitionRcp := range frameworkRcp.Definitions {
+			if definitionRcp == nil {
+				continue
+			}
+			definitionID := output.addNode(GraphNodeDefinition, frameworkRcp.Name+"/"+definitionRcp.Name, definitionRcp.Name)
+			output.addEdge(frameworkID, definitionID)
+		}
+		for _, schemaRcp := range frameworkRcp.Schemas {
+			if schemaRcp == nil {
+				continue
+			}
+			schemaID := output.addNode(GraphNodeSchema, frameworkRcp.Name+"/"+schemaRcp.Name, schemaRcp.Name)
+			output.addEdge(frameworkID, schemaID)
+			output.addTargets(schemaID, schemaRcp.Output)
+		}
+		for _, procedureRcp := range frameworkRcp.Procedures {
+			if procedureRcp == nil {
+				continue
+			}
+			procedureID := output.addNode(GraphNodeProcedure, frameworkRcp.Name+"/"+procedureRcp.Name, procedureRcp.Name)
+			output.addEdge(frameworkID, procedureID)
+			for _, input := range procedureRcp.Inputs {
+				inputID := output.addNode(GraphNodeProcedure, frameworkRcp.Name+"/"+input, input)
+				output.addEdge(inputID, procedureID)
+			}
+			output.addTargets(procedureID, procedureRcp.Output)
+		}
+	}
+	return output, nil
+}
+
+// addTargets connects the step to its output targets, where a target is identified by its type and path,
+// so the steps writing to the same stream or file share the same node
+func (g *Graph) addTargets(stepID string, output *Output) {
+	if output == nil {
+		return
+	}
+	for _, t := range output.Targets {
+		if t == nil {
+			continue
+		}
+		name := t.Type
+		if t.Path != "" {
+			name = fmt.Sprintf("%s: %s", t.Type, t.Path)
+		}
+		targetID := g.addNode(GraphNodeTarget, name, name)
+		g.addEdge(stepID, targetID)
+	}
+}
+
+func (g *Graph) addNode(kind, name, label string) string {
+	id := kind + ":" + name
+	if g.idToNode[id] == nil {
+		node := &GraphNode{
+			ID:    id,
+			Kind:  kind,
+			Label: label,
+		}
+		g.idToNode[id] = node
+		g.Nodes = append(g.Nodes, node)
+	}
+	return id
+}
+
+func (g *Graph) addEdge(from, to string) {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, &GraphEdge{
+		From: from,
+		To:   to,
+	})
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *Graph) DOT() string {
+	lines := []string{
+		"digraph valor {",
+		"  rankdir=LR;",
+	}
+	for _, n := range g.Nodes {
+		lines = append(lines, fmt.Sprintf("  %s [label=%s, shape=%s];",
+			quoteDOT(n.ID), quoteDOT(n.Kind+": "+n.Label), graphNodeKindToDOTShape[n.Kind],
+		))
+	}
+	for _, e := range g.Edges {
+		lines = append(lines, fmt.Sprintf("  %s -> %s;", quoteDOT(e.From), quoteDOT(e.To)))
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	// Mermaid only accepts simple identifiers, so nodes are numbered by their order
+	idToMermaidID := make(map[string]string)
+	lines := []string{"flowchart LR"}
+	for i, n := range g.Nodes {
+		mermaidID := fmt.Sprintf("n%d", i)
+		idToMermaidID[n.ID] = mermaidID
+		lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", mermaidID, escapeMermaid(n.Kind+": "+n.Label)))
+	}
+	for _, e := range g.Edges {
+		lines = append(lines, fmt.Sprintf("  %s --> %s", idToMermaidID[e.From], idToMermaidID[e.To]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func quoteDOT(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	return "\"" + value + "\""
+}
+
+func escapeMermaid(value string) string {
+	return strings.ReplaceAll(value, "\"", "#quot;")
+}
@@ -0,0 +1,146 @@
+package recipe_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildGraph(t *testing.T) {
+	t.Run("should return nil and error if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		actualGraph, actualErr := recipe.BuildGraph(rcp)
+
+		assert.Nil(t, actualGraph)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return graph from resource up to output target", func(t *testing.T) {
+		rcp := newGraphRecipe()
+
+		expectedEdges := []*recipe.GraphEdge{
+			{From: "resource:resource", To: "framework:framework"},
+			{From: "framework:framework", To: "definition:framework/definition"},
+			{From: "framework:framework", To: "schema:framework/schema"},
+			{From: "framework:framework", To: "procedure:framework/procedure"},
+			{From: "procedure:framework/procedure", To: "target:file: ./out"},
+		}
+
+		actualGraph, actualErr := recipe.BuildGraph(rcp)
+
+		assert.Nil(t, actualErr)
+		assert.Len(t, actualGraph.Nodes, 6)
+		assert.EqualValues(t, expectedEdges, actualGraph.Edges)
+	})
+
+	t.Run("should share the target node between steps writing to the same type and path", func(t *testing.T) {
+		newOutput := func(name, _type, path string) *recipe.Output {
+			return &recipe.Output{
+				TreatAs: "success",
+				Targets: []*recipe.Target{
+					{Name: name, Type: _type, Format: "json", Path: path},
+				},
+			}
+		}
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "framework",
+					Schemas: []*recipe.Schema{
+						{Name: "schema", Output: newOutput("schema_output", "std", "")},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "first", Output: newOutput("first_output", "file", "./out")},
+						{Name: "second", Output: newOutput("second_output", "file", "./out")},
+						{Name: "third", Output: newOutput("third_output", "std", "")},
+					},
+				},
+			},
+		}
+
+		expectedTargetEdges := []*recipe.GraphEdge{
+			{From: "schema:framework/schema", To: "target:std"},
+			{From: "procedure:framework/first", To: "target:file: ./out"},
+			{From: "procedure:framework/second", To: "target:file: ./out"},
+			{From: "procedure:framework/third", To: "target:std"},
+		}
+
+		actualGraph, actualErr := recipe.BuildGraph(rcp)
+
+		assert.Nil(t, actualErr)
+		var actualTargetEdges []*recipe.GraphEdge
+		var actualTargetNodes int
+		for _, e := range actualGraph.Edges {
+			if strings.HasPrefix(e.To, recipe.GraphNodeTarget+":") {
+				actualTargetEdges = append(actualTargetEdges, e)
+			}
+		}
+		for _, n := range actualGraph.Nodes {
+			if n.Kind == recipe.GraphNodeTarget {
+				actualTargetNodes++
+			}
+		}
+		assert.EqualValues(t, expectedTargetEdges, actualTargetEdges)
+		assert.Equal(t, 2, actualTargetNodes)
+	})
+}
+
+func TestGraphDOT(t *testing.T) {
+	t.Run("should render nodes with quoted identifier and edges", func(t *testing.T) {
+		graph, err := recipe.BuildGraph(newGraphRecipe())
+		assert.Nil(t, err)
+
+		actualValue := graph.DOT()
+
+		assert.Contains(t, actualValue, "digraph valor {")
+		assert.Contains(t, actualValue, `"target:file: ./out" [label="target: file: ./out", shape=cylinder];`)
+		assert.Contains(t, actualValue, `"resource:resource" -> "framework:framework";`)
+	})
+}
+
+func TestGraphMermaid(t *testing.T) {
+	t.Run("should render nodes with numbered identifier and edges", func(t *testing.T) {
+		graph, err := recipe.BuildGraph(newGraphRecipe())
+		assert.Nil(t, err)
+
+		actualValue := graph.Mermaid()
+
+		assert.Contains(t, actualValue, "flowchart LR")
+		assert.Contains(t, actualValue, `n0["resource: resource"]`)
+		assert.Contains(t, actualValue, "n0 --> n1")
+	})
+}
+
+func newGraphRecipe() *recipe.Recipe {
+	return &recipe.Recipe{
+		Resources: []*recipe.Resource{
+			{Name: "resource", FrameworkNames: []string{"framework"}},
+		},
+		Frameworks: []*recipe.Framework{
+			{
+				Name: "framework",
+				Definitions: []*recipe.Definition{
+					{Name: "definition"},
+				},
+				Schemas: []*recipe.Schema{
+					{Name: "schema"},
+				},
+				Procedures: []*recipe.Procedure{
+					{
+						Name: "procedure",
+						Output: &recipe.Output{
+							TreatAs: "success",
+							Targets: []*recipe.Target{
+								{Name: "output", Type: "file", Format: "json", Path: "./out"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
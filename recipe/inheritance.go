@@ -0,0 +1,158 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResolveExtends resolves every framework that extends another framework.
+// A framework inherits schemas, definitions, and procedures of its parent,
+// where an entry with the same name as the parent's overrides it in place
+// and other entries are appended after the parent's entries.
+func ResolveExtends(rcp *Recipe) error {
+	if rcp == nil {
+		return errors.New("recipe is nil")
+	}
+	if err := validateAllExtends(rcp.Frameworks); err != nil {
+		return err
+	}
+	nameToFramework := getNameToFramework(rcp.Frameworks)
+	resolved := make(map[string]bool)
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		if err := resolveFramework(frameworkRcp, nameToFramework, resolved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveFramework(frameworkRcp *Framework, nameToFramework map[string]*Framework, resolved map[string]bool) error {
+	if frameworkRcp.Extends == "" || resolved[frameworkRcp.Name] {
+		return nil
+	}
+	parent := nameToFramework[frameworkRcp.Extends]
+	if err := resolveFramework(parent, nameToFramework, resolved); err != nil {
+		return err
+	}
+
+	schemas, err := mergeByName("schema", parent.Schemas, frameworkRcp.Schemas)
+	if err != nil {
+		return fmt.Errorf("framework recipe [%s]: %v", frameworkRcp.Name, err)
+	}
+	definitions, err := mergeByName("definition", parent.Definitions, frameworkRcp.Definitions)
+	if err != nil {
+		return fmt.Errorf("framework recipe [%s]: %v", frameworkRcp.Name, err)
+	}
+	procedures, err := mergeByName("procedure", parent.Procedures, frameworkRcp.Procedures)
+	if err != nil {
+		return fmt.Errorf("framework recipe [%s]: %v", frameworkRcp.Name, err)
+	}
+	frameworkRcp.Schemas = schemas.([]*Schema)
+	frameworkRcp.Definitions = definitions.([]*Definition)
+	frameworkRcp.Procedures = procedures.([]*Procedure)
+	resolved[frameworkRcp.Name] = true
+	return nil
+}
+
+// mergeByName merges the child entries into the parent entries, where both are slices
+// of pointer to a recipe with Name field. A child entry with the same name as a parent entry
+// replaces it in place, and other child entries are appended after the parent entries.
+// The child should not have duplicate names, since only one of them could replace the parent entry.
+func mergeByName(kind string, parent, child interface{}) (interface{}, error) {
+	parentValue, childValue := reflect.ValueOf(parent), reflect.ValueOf(child)
+	getName := func(entry reflect.Value) (string, bool) {
+		if entry.IsNil() {
+			return "", false
+		}
+		return entry.Elem().FieldByName("Name").String(), true
+	}
+	nameToChild := make(map[string]reflect.Value)
+	var duplicateNames []string
+	for i := 0; i < childValue.Len(); i++ {
+		name, ok := getName(childValue.Index(i))
+		if !ok {
+			continue
+		}
+		if nameToChild[name].IsValid() {
+			duplicateNames = append(duplicateNames, name)
+		}
+		nameToChild[name] = childValue.Index(i)
+	}
+	if len(duplicateNames) > 0 {
+		return nil, fmt.Errorf("duplicate %s recipe [%s]", kind, strings.Join(duplicateNames, ", "))
+	}
+	output := reflect.Zero(parentValue.Type())
+	overridden := make(map[string]bool)
+	for i := 0; i < parentValue.Len(); i++ {
+		entry := parentValue.Index(i)
+		if name, ok := getName(entry); ok && nameToChild[name].IsValid() {
+			entry = nameToChild[name]
+			overridden[name] = true
+		}
+		output = reflect.Append(output, entry)
+	}
+	for i := 0; i < childValue.Len(); i++ {
+		entry := childValue.Index(i)
+		if name, ok := getName(entry); !ok || !overridden[name] {
+			output = reflect.Append(output, entry)
+		}
+	}
+	return output.Interface(), nil
+}
+
+// copyResolvedFrameworks resolves the extends on copies of the frameworks,
+// so the inherited entries can be inspected without modifying the frameworks
+func copyResolvedFrameworks(rcps []*Framework) ([]*Framework, error) {
+	output := make([]*Framework, len(rcps))
+	for i, frameworkRcp := range rcps {
+		if frameworkRcp != nil {
+			copied := *frameworkRcp
+			output[i] = &copied
+		}
+	}
+	if err := ResolveExtends(&Recipe{Frameworks: output}); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func validateAllExtends(rcps []*Framework) error {
+	nameToFramework := getNameToFramework(rcps)
+	for _, frameworkRcp := range rcps {
+		if frameworkRcp == nil || frameworkRcp.Extends == "" {
+			continue
+		}
+		if nameToFramework[frameworkRcp.Extends] == nil {
+			return fmt.Errorf("framework recipe [%s] extends unknown framework [%s]",
+				frameworkRcp.Name, frameworkRcp.Extends,
+			)
+		}
+		chain := []string{frameworkRcp.Name}
+		visited := map[string]bool{frameworkRcp.Name: true}
+		current := nameToFramework[frameworkRcp.Extends]
+		for current != nil {
+			chain = append(chain, current.Name)
+			if visited[current.Name] {
+				return fmt.Errorf("framework recipe extends cycle [%s]", strings.Join(chain, " -> "))
+			}
+			visited[current.Name] = true
+			current = nameToFramework[current.Extends]
+		}
+	}
+	return nil
+}
+
+func getNameToFramework(rcps []*Framework) map[string]*Framework {
+	output := make(map[string]*Framework)
+	for _, frameworkRcp := range rcps {
+		if frameworkRcp != nil {
+			output[frameworkRcp.Name] = frameworkRcp
+		}
+	}
+	return output
+}
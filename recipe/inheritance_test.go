@@ -0,0 +1,143 @@
+package recipe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveExtends(t *testing.T) {
+	t.Run("should return error if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		actualErr := recipe.ResolveExtends(rcp)
+
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return error if parent framework is unknown", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name:    "child",
+					Extends: "unknown",
+				},
+			},
+		}
+
+		expectedErr := errors.New("framework recipe [child] extends unknown framework [unknown]")
+
+		actualErr := recipe.ResolveExtends(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should return error if extends contains cycle", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name:    "first",
+					Extends: "second",
+				},
+				{
+					Name:    "second",
+					Extends: "first",
+				},
+			},
+		}
+
+		expectedErr := errors.New("framework recipe extends cycle [first -> second -> first]")
+
+		actualErr := recipe.ResolveExtends(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should return error if child framework has duplicate names", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Path: "parent/enrich.jsonnet"},
+					},
+				},
+				{
+					Name:    "child",
+					Extends: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Path: "child/first.jsonnet"},
+						{Name: "enrich", Path: "child/second.jsonnet"},
+					},
+				},
+			},
+		}
+
+		expectedErr := errors.New("framework recipe [child]: duplicate procedure recipe [enrich]")
+
+		actualErr := recipe.ResolveExtends(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should inherit, override, and append entries by name", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name:    "grandchild",
+					Extends: "child",
+					Procedures: []*recipe.Procedure{
+						{Name: "finalize", Path: "grandchild/finalize.jsonnet"},
+					},
+				},
+				{
+					Name:    "child",
+					Extends: "parent",
+					Definitions: []*recipe.Definition{
+						{Name: "extra", Path: "child/extra"},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Path: "child/enrich.jsonnet"},
+						{Name: "summarize", Path: "child/summarize.jsonnet"},
+					},
+				},
+				{
+					Name: "parent",
+					Schemas: []*recipe.Schema{
+						{Name: "rule", Path: "parent/rule.json"},
+					},
+					Definitions: []*recipe.Definition{
+						{Name: "memberships", Path: "parent/memberships"},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Path: "parent/enrich.jsonnet"},
+						{Name: "validate", Path: "parent/validate.jsonnet"},
+					},
+				},
+			},
+		}
+
+		actualErr := recipe.ResolveExtends(rcp)
+
+		assert.Nil(t, actualErr)
+		child := rcp.Frameworks[1]
+		assert.Len(t, child.Schemas, 1)
+		assert.Equal(t, "parent/rule.json", child.Schemas[0].Path)
+		assert.Len(t, child.Definitions, 2)
+		assert.Equal(t, "memberships", child.Definitions[0].Name)
+		assert.Equal(t, "extra", child.Definitions[1].Name)
+		assert.Len(t, child.Procedures, 3)
+		assert.Equal(t, "child/enrich.jsonnet", child.Procedures[0].Path)
+		assert.Equal(t, "parent/validate.jsonnet", child.Procedures[1].Path)
+		assert.Equal(t, "child/summarize.jsonnet", child.Procedures[2].Path)
+		grandchild := rcp.Frameworks[0]
+		assert.Len(t, grandchild.Definitions, 2)
+		assert.Len(t, grandchild.Procedures, 4)
+		assert.Equal(t, "grandchild/finalize.jsonnet", grandchild.Procedures[3].Path)
+		parent := rcp.Frameworks[2]
+		assert.Len(t, parent.Procedures, 2)
+	})
+}
@@ -0,0 +1,99 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const escapedPlaceholder = "$${"
+
+var variablePattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z0-9_.-]+)(:-([^}]*))?\}`)
+
+// LookupVariable looks up the value of a variable by its name
+type LookupVariable func(name string) (string, bool)
+
+// Interpolate replaces every ${VAR} and ${VAR:-default} within the string fields
+// of the recipe. Variable is looked up from params first, then from lookupEnv.
+// A literal "${" can be written by escaping the dollar sign, like "$${".
+// Any other "$", including "$$", is kept as it is.
+// Error is returned with every variable that cannot be resolved.
+func Interpolate(rcp *Recipe, params map[string]string, lookupEnv LookupVariable) error {
+	if rcp == nil {
+		return errors.New("recipe is nil")
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := params[name]; ok {
+			return value, true
+		}
+		if lookupEnv != nil {
+			return lookupEnv(name)
+		}
+		return "", false
+	}
+	unresolved := make(map[string]bool)
+	interpolateValue(reflect.ValueOf(rcp), lookup, unresolved)
+	if len(unresolved) > 0 {
+		var names []string
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unresolved variable [%s]", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func interpolateValue(value reflect.Value, lookup LookupVariable, unresolved map[string]bool) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			interpolateValue(value.Elem(), lookup, unresolved)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			interpolateValue(value.Field(i), lookup, unresolved)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			interpolateValue(value.Index(i), lookup, unresolved)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			mapValue := reflect.New(iter.Value().Type()).Elem()
+			mapValue.Set(iter.Value())
+			interpolateValue(mapValue, lookup, unresolved)
+			value.SetMapIndex(iter.Key(), mapValue)
+		}
+	case reflect.String:
+		if value.CanSet() {
+			value.SetString(interpolateString(value.String(), lookup, unresolved))
+		}
+	}
+}
+
+func interpolateString(input string, lookup LookupVariable, unresolved map[string]bool) string {
+	return variablePattern.ReplaceAllStringFunc(input, func(match string) string {
+		if match == escapedPlaceholder {
+			return "${"
+		}
+		submatch := variablePattern.FindStringSubmatch(match)
+		name, hasDefault, defaultValue := submatch[1], submatch[2] != "", submatch[3]
+		value, ok := lookup(name)
+		if ok && (value != "" || !hasDefault) {
+			return value
+		}
+		if hasDefault {
+			return defaultValue
+		}
+		unresolved[name] = true
+		return match
+	})
+}
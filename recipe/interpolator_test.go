@@ -0,0 +1,138 @@
+package recipe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{
+		"RESOURCE_DIR": "./env/resource",
+		"EMPTY":        "",
+		"FORMAT":       "json",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	t.Run("should return error if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		actualErr := recipe.Interpolate(rcp, nil, lookupEnv)
+
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should replace variables from environment and default value", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "user_account",
+					Format:         "${FORMAT}",
+					Path:           "${RESOURCE_DIR}/users",
+					RegexPattern:   "${PATTERN:-[A-Z]}",
+					FrameworkNames: []string{"${FRAMEWORK:-evaluation}", "${EMPTY:-fallback}"},
+				},
+			},
+		}
+
+		actualErr := recipe.Interpolate(rcp, nil, lookupEnv)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, "json", rcp.Resources[0].Format)
+		assert.Equal(t, "./env/resource/users", rcp.Resources[0].Path)
+		assert.Equal(t, "[A-Z]", rcp.Resources[0].RegexPattern)
+		assert.Equal(t, []string{"evaluation", "fallback"}, rcp.Resources[0].FrameworkNames)
+	})
+
+	t.Run("should prioritize params over environment", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluation",
+					Procedures: []*recipe.Procedure{
+						{
+							Path: "${RESOURCE_DIR}",
+							Output: &recipe.Output{
+								Targets: []*recipe.Target{
+									{
+										Path: "${OUTPUT_DIR}",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		params := map[string]string{
+			"RESOURCE_DIR": "./param/resource",
+			"OUTPUT_DIR":   "./param/output",
+		}
+
+		actualErr := recipe.Interpolate(rcp, params, lookupEnv)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, "./param/resource", rcp.Frameworks[0].Procedures[0].Path)
+		assert.Equal(t, "./param/output", rcp.Frameworks[0].Procedures[0].Output.Targets[0].Path)
+	})
+
+	t.Run("should keep escaped variable as literal", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					RegexPattern: "$${RESOURCE_DIR}$",
+				},
+			},
+		}
+
+		actualErr := recipe.Interpolate(rcp, nil, lookupEnv)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, "${RESOURCE_DIR}$", rcp.Resources[0].RegexPattern)
+	})
+
+	t.Run("should keep dollar signs that are not followed by brace", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					RegexPattern: "^price_$$[0-9]+$$",
+					Path:         "echo $$ $HOME",
+				},
+			},
+		}
+
+		actualErr := recipe.Interpolate(rcp, nil, lookupEnv)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, "^price_$$[0-9]+$$", rcp.Resources[0].RegexPattern)
+		assert.Equal(t, "echo $$ $HOME", rcp.Resources[0].Path)
+	})
+
+	t.Run("should return error listing every unresolved variable", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name: "${NAME}",
+					Path: "${UNKNOWN}/${NAME}",
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "${ANOTHER}",
+				},
+			},
+		}
+
+		expectedErr := errors.New("unresolved variable [ANOTHER, NAME, UNKNOWN]")
+
+		actualErr := recipe.Interpolate(rcp, nil, lookupEnv)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+}
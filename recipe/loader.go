@@ -3,11 +3,24 @@ package recipe
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"path/filepath"
 
 	"github.com/gojek/optimus-extension-valor/model"
 )
 
-// Load loads recipe from the passed Reader with Decoder to decode
+// NewReader initializes Reader to read recipe from the path
+type NewReader func(path string) model.Reader
+
+// GetDecode gets Decode to decode recipe from the path
+type GetDecode func(path string) (model.Decode, error)
+
+// InterpolateRecipe interpolates the variables within a recipe
+type InterpolateRecipe func(rcp *Recipe) error
+
+// Load loads recipe from the passed Reader with Decoder to decode.
+// The content is decoded as it is, only the enum fields are normalized.
+// Recipe with older apiVersion is migrated in memory to CurrentAPIVersion.
 func Load(reader model.Reader, decode model.Decode) (*Recipe, error) {
 	if reader == nil {
 		return nil, errors.New("reader is nil")
@@ -27,5 +40,110 @@ func Load(reader model.Reader, decode model.Decode) (*Recipe, error) {
 	if err != nil {
 		return nil, err
 	}
+	if output.APIVersion != CurrentAPIVersion {
+		if err := migrateContent(data.Content, output); err != nil {
+			return nil, err
+		}
+	}
+	Normalize(output)
+	return output, nil
+}
+
+// LoadAll loads recipe from the path along with all of its included recipes.
+// Included path is relative to the recipe that includes it, and every recipe
+// file is only loaded once. Resources and frameworks of the included recipes
+// are merged into the output, where each of them records its origin path.
+// Shared outputs of all recipes are merged, where each name should only be defined once.
+// Jsonnet library paths of all recipes are merged, where each path is only kept once,
+// and the first specified Jsonnet pool size is used.
+// Defaults of a recipe only apply to the resources and frameworks defined in the same recipe.
+// Each recipe is decoded by Decode based on its own path.
+// If interpolate is not nil, each recipe is interpolated once it is loaded,
+// so the variables within its included paths are resolved before they are loaded.
+func LoadAll(path string, newReader NewReader, getDecode GetDecode, interpolate InterpolateRecipe) (*Recipe, error) {
+	if newReader == nil {
+		return nil, errors.New("newReader is nil")
+	}
+	if getDecode == nil {
+		return nil, errors.New("getDecode is nil")
+	}
+	output := &Recipe{
+		APIVersion: CurrentAPIVersion,
+	}
+	loaded := make(map[string]bool)
+	if err := loadInto(output, path, newReader, getDecode, interpolate, loaded); err != nil {
+		return nil, err
+	}
 	return output, nil
 }
+
+func loadInto(output *Recipe, path string, newReader NewReader, getDecode GetDecode, interpolate InterpolateRecipe, loaded map[string]bool) error {
+	cleanPath := filepath.Clean(path)
+	if loaded[cleanPath] {
+		return nil
+	}
+	loaded[cleanPath] = true
+
+	decode, err := getDecode(path)
+	if err != nil {
+		return err
+	}
+	rcp, err := Load(newReader(path), decode)
+	if err != nil {
+		return err
+	}
+	if interpolate != nil {
+		if err := interpolate(rcp); err != nil {
+			return err
+		}
+	}
+	ApplyDefaults(rcp)
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp != nil {
+			resourceRcp.Origin = path
+		}
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp != nil {
+			frameworkRcp.Origin = path
+		}
+	}
+	for name, o := range rcp.Outputs {
+		if _, ok := output.Outputs[name]; ok {
+			return fmt.Errorf("output [%s] in %s is already defined", name, path)
+		}
+		if output.Outputs == nil {
+			output.Outputs = make(map[string]*Output)
+		}
+		output.Outputs[name] = o
+	}
+	if rcp.Jsonnet != nil {
+		if output.Jsonnet == nil {
+			output.Jsonnet = &Jsonnet{}
+		}
+		if output.Jsonnet.PoolSize == 0 {
+			output.Jsonnet.PoolSize = rcp.Jsonnet.PoolSize
+		}
+		for _, libraryPath := range rcp.Jsonnet.LibraryPaths {
+			if !containsAny(output.Jsonnet.LibraryPaths, []string{libraryPath}) {
+				output.Jsonnet.LibraryPaths = append(output.Jsonnet.LibraryPaths, libraryPath)
+			}
+		}
+	}
+	output.Include = append(output.Include, rcp.Include...)
+	output.Defaulted = append(output.Defaulted, rcp.Defaulted...)
+	output.Resources = append(output.Resources, rcp.Resources...)
+	output.Frameworks = append(output.Frameworks, rcp.Frameworks...)
+
+	for _, includePath := range rcp.Include {
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+		if err := loadInto(output, includePath, newReader, getDecode, interpolate, loaded); err != nil {
+			outputError := &model.Error{}
+			outputError.Add(includePath, err)
+			return outputError
+		}
+	}
+	return nil
+}
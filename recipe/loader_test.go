@@ -6,6 +6,7 @@ import (
 
 	"github.com/gojek/optimus-extension-valor/mocks"
 	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/endec/yaml"
 	"github.com/gojek/optimus-extension-valor/recipe"
 
 	"github.com/stretchr/testify/assert"
@@ -100,3 +101,166 @@ func TestLoad(t *testing.T) {
 		assert.Nil(t, actualErr)
 	})
 }
+
+func TestLoadNormalization(t *testing.T) {
+	t.Run("should preserve case of non-enum fields and lowercase enum fields", func(t *testing.T) {
+		reader := &mocks.Reader{}
+		reader.On("Read").Return(&model.Data{
+			Content: []byte(`
+resources:
+- name: User_Account
+  type: FILE
+  format: JSON
+  path: ./Example/Resource
+  regex_pattern: "[A-Z]"
+`),
+		}, nil)
+
+		actualRecipe, actualErr := recipe.Load(reader, yaml.NewDecode())
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, "User_Account", actualRecipe.Resources[0].Name)
+		assert.Equal(t, "file", actualRecipe.Resources[0].Type)
+		assert.Equal(t, "json", actualRecipe.Resources[0].Format)
+		assert.Equal(t, "./Example/Resource", actualRecipe.Resources[0].Path)
+		assert.Equal(t, "[A-Z]", actualRecipe.Resources[0].RegexPattern)
+	})
+}
+
+func TestLoadAll(t *testing.T) {
+	pathToContent := map[string]string{
+		"valor.yaml": `
+include:
+- sub/extra.yaml
+resources:
+- name: resource1
+frameworks:
+- name: framework1
+jsonnet:
+  library_paths:
+  - lib
+  pool_size: 2
+`,
+		"sub/extra.yaml": `
+include:
+- ../valor.yaml
+- another.yaml
+resources:
+- name: resource2
+`,
+		"sub/another.yaml": `
+frameworks:
+- name: framework2
+jsonnet:
+  library_paths:
+  - lib
+  - vendor
+  pool_size: 8
+`,
+		"interpolated.yaml": `
+include:
+- ${SUB_DIR}/another.yaml
+- $${SUB_DIR}/escaped.yaml
+`,
+		"${SUB_DIR}/escaped.yaml": `
+resources:
+- name: escaped
+`,
+		"invalid.yaml": `
+include:
+- unknown.yaml
+resources:
+- name: resource1
+`,
+	}
+	newReader := func(path string) model.Reader {
+		reader := &mocks.Reader{}
+		content, ok := pathToContent[path]
+		if !ok {
+			reader.On("Read").Return(nil, errors.New("not found"))
+		} else {
+			reader.On("Read").Return(&model.Data{
+				Content: []byte(content),
+				Path:    path,
+			}, nil)
+		}
+		return reader
+	}
+	getDecode := func(path string) (model.Decode, error) {
+		return yaml.NewDecode(), nil
+	}
+
+	t.Run("should return nil and error if newReader is nil", func(t *testing.T) {
+		var newReader recipe.NewReader = nil
+
+		actualRecipe, actualErr := recipe.LoadAll("valor.yaml", newReader, getDecode, nil)
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if getDecode is nil", func(t *testing.T) {
+		var getDecode recipe.GetDecode = nil
+
+		actualRecipe, actualErr := recipe.LoadAll("valor.yaml", newReader, getDecode, nil)
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if decode cannot be found", func(t *testing.T) {
+		getDecode := func(path string) (model.Decode, error) {
+			return nil, errors.New("decode not found")
+		}
+
+		actualRecipe, actualErr := recipe.LoadAll("valor.yaml", newReader, getDecode, nil)
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if included recipe cannot be loaded", func(t *testing.T) {
+		actualRecipe, actualErr := recipe.LoadAll("invalid.yaml", newReader, getDecode, nil)
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return merged recipe with origin and nil if no error is encountered", func(t *testing.T) {
+		actualRecipe, actualErr := recipe.LoadAll("valor.yaml", newReader, getDecode, nil)
+
+		assert.Nil(t, actualErr)
+		assert.Len(t, actualRecipe.Resources, 2)
+		assert.Equal(t, "valor.yaml", actualRecipe.Resources[0].Origin)
+		assert.Equal(t, "sub/extra.yaml", actualRecipe.Resources[1].Origin)
+		assert.Len(t, actualRecipe.Frameworks, 2)
+		assert.Equal(t, "valor.yaml", actualRecipe.Frameworks[0].Origin)
+		assert.Equal(t, "sub/another.yaml", actualRecipe.Frameworks[1].Origin)
+		assert.Equal(t, []string{"lib", "vendor"}, actualRecipe.Jsonnet.LibraryPaths)
+		assert.Equal(t, 2, actualRecipe.Jsonnet.PoolSize)
+	})
+	t.Run("should interpolate each recipe before loading its included paths", func(t *testing.T) {
+		interpolate := func(rcp *recipe.Recipe) error {
+			return recipe.Interpolate(rcp, map[string]string{"SUB_DIR": "sub"}, nil)
+		}
+
+		actualRecipe, actualErr := recipe.LoadAll("interpolated.yaml", newReader, getDecode, interpolate)
+
+		assert.Nil(t, actualErr)
+		assert.Len(t, actualRecipe.Frameworks, 1)
+		assert.Equal(t, "sub/another.yaml", actualRecipe.Frameworks[0].Origin)
+		assert.Len(t, actualRecipe.Resources, 1)
+		assert.Equal(t, "escaped", actualRecipe.Resources[0].Name)
+	})
+
+	t.Run("should return nil and error if interpolate returns error", func(t *testing.T) {
+		interpolate := func(rcp *recipe.Recipe) error {
+			return recipe.Interpolate(rcp, nil, nil)
+		}
+
+		actualRecipe, actualErr := recipe.LoadAll("interpolated.yaml", newReader, getDecode, interpolate)
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+}
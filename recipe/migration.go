@@ -0,0 +1,388 @@
+package recipe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LegacyAPIVersion is the version of a recipe that does not specify apiVersion
+	LegacyAPIVersion = ""
+	// CurrentAPIVersion is the latest version of the recipe format
+	CurrentAPIVersion = "v1"
+
+	apiVersionKey   = "apiVersion"
+	migrationIndent = 2
+)
+
+// Migrate migrates the root mapping node of a recipe into the next version
+type Migrate func(node *yaml.Node) error
+
+type migration struct {
+	to      string
+	migrate Migrate
+}
+
+// MigrationFactory is a factory for Migrate
+type MigrationFactory struct {
+	versionToMigration map[string]*migration
+}
+
+// Register registers a migration from a version into the next version
+func (m *MigrationFactory) Register(from, to string, fn Migrate) error {
+	if fn == nil {
+		return errors.New("Migrate is nil")
+	}
+	if from == to {
+		return fmt.Errorf("[%s] cannot be migrated into itself", getVersionName(from))
+	}
+	if m.versionToMigration[from] != nil {
+		return fmt.Errorf("[%s] is already registered", getVersionName(from))
+	}
+	m.versionToMigration[from] = &migration{
+		to:      to,
+		migrate: fn,
+	}
+	return nil
+}
+
+// Migrate migrates the node of a recipe document, in place, from its version up to
+// CurrentAPIVersion. It returns the versions that the node is migrated through.
+func (m *MigrationFactory) Migrate(node *yaml.Node) ([]string, error) {
+	mapping := getDocumentMapping(node)
+	if mapping == nil {
+		return nil, errors.New("recipe document should be a mapping")
+	}
+	version := getAPIVersion(mapping)
+	var output []string
+	visited := make(map[string]bool)
+	for version != CurrentAPIVersion {
+		if visited[version] {
+			return nil, fmt.Errorf("migration from [%s] contains a cycle", getVersionName(version))
+		}
+		visited[version] = true
+		mgr := m.versionToMigration[version]
+		if mgr == nil {
+			return nil, fmt.Errorf("apiVersion [%s] is not supported", getVersionName(version))
+		}
+		if err := mgr.migrate(mapping); err != nil {
+			return nil, err
+		}
+		setAPIVersion(mapping, mgr.to)
+		version = mgr.to
+		output = append(output, version)
+	}
+	return output, nil
+}
+
+// NewMigrationFactory initializes factory Migrate
+func NewMigrationFactory() *MigrationFactory {
+	return &MigrationFactory{
+		versionToMigration: make(map[string]*migration),
+	}
+}
+
+// Migrations is a factory for migrations of the recipe format
+var Migrations = NewMigrationFactory()
+
+// DetectAPIVersion detects apiVersion of a recipe content without decoding it fully.
+// It returns LegacyAPIVersion if the content does not specify one.
+func DetectAPIVersion(content []byte) (string, error) {
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(content, node); err != nil {
+		return "", err
+	}
+	mapping := getDocumentMapping(node)
+	if mapping == nil {
+		return LegacyAPIVersion, nil
+	}
+	return getAPIVersion(mapping), nil
+}
+
+// MigrateContent migrates a recipe content up to CurrentAPIVersion while keeping every line not changed by
+// the migration as it is, including its indentation, blank lines and comments. If the changes cannot be
+// applied line by line, then the whole content is rewritten, where only the comments are preserved.
+// It returns the migrated content along with the versions that the content is migrated through.
+// If the content is already at CurrentAPIVersion, then it is returned as it is.
+func MigrateContent(content []byte) ([]byte, []string, error) {
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(content, node); err != nil {
+		return nil, nil, err
+	}
+	nodeToValue := make(map[*yaml.Node]string)
+	walkNode(node, func(n *yaml.Node) {
+		nodeToValue[n] = n.Value
+	})
+	versions, err := Migrations.Migrate(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(versions) == 0 {
+		return content, nil, nil
+	}
+	if output, ok := patchContent(content, node, nodeToValue); ok {
+		return output, versions, nil
+	}
+	buff := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buff)
+	encoder.SetIndent(migrationIndent)
+	if err := encoder.Encode(node); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buff.Bytes(), versions, nil
+}
+
+// patchContent applies the changes of the migrated node onto the original content, where nodeToValue
+// holds every node before the migration along with its value. The supported changes are removed entries,
+// changed scalar values, and scalar entries added to the root mapping. It returns false if the changes are
+// not supported or if the patched content does not result in the same recipe as the migrated node.
+func patchContent(content []byte, node *yaml.Node, nodeToValue map[*yaml.Node]string) ([]byte, bool) {
+	mapping := getDocumentMapping(node)
+	if mapping == nil {
+		return nil, false
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	lineToReplacement := make(map[int]string)
+	keptLines := make(map[int]bool)
+	supported := true
+	walkNode(node, func(n *yaml.Node) {
+		value, existed := nodeToValue[n]
+		switch {
+		case existed:
+			keptLines[n.Line] = true
+			if n.Value != value {
+				replacement, ok := replaceScalar(lines, n)
+				supported = supported && ok
+				lineToReplacement[n.Line] = replacement
+			}
+		case n.Kind != yaml.ScalarNode:
+			supported = false
+		case !isRootEntry(mapping, n):
+			supported = false
+		}
+	})
+	var additions []string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if _, existed := nodeToValue[key]; !existed {
+			additions = append(additions, fmt.Sprintf("%s: %s\n", key.Value, value.Value))
+		}
+	}
+	if !supported {
+		return nil, false
+	}
+	nodeLines := make(map[int]bool)
+	for n := range nodeToValue {
+		nodeLines[n.Line] = true
+	}
+	// the added entries are put right before the first existing entry, after the leading comment
+	var firstKeyLine int
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if _, existed := nodeToValue[mapping.Content[i]]; existed {
+			firstKeyLine = mapping.Content[i].Line
+			break
+		}
+	}
+	buff := &bytes.Buffer{}
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		if lineNumber == firstKeyLine {
+			buff.WriteString(strings.Join(additions, ""))
+			additions = nil
+		}
+		if !nodeLines[lineNumber] || keptLines[lineNumber] {
+			if replacement, ok := lineToReplacement[lineNumber]; ok {
+				buff.WriteString(replacement)
+			} else {
+				buff.WriteString(lines[i])
+			}
+			continue
+		}
+		// the lines of a removed entry are the ones indented deeper than its first line
+		indent := getIndent(lines[i])
+		for i+1 < len(lines) && !nodeLines[i+2] && isDeeper(lines[i+1], indent) {
+			i++
+		}
+	}
+	buff.WriteString(strings.Join(additions, ""))
+	if !isSameContent(buff.Bytes(), node) {
+		return nil, false
+	}
+	return buff.Bytes(), true
+}
+
+func replaceScalar(lines []string, node *yaml.Node) (string, bool) {
+	if node.Kind != yaml.ScalarNode || node.Line < 1 || node.Line > len(lines) {
+		return "", false
+	}
+	line := lines[node.Line-1]
+	if node.Column < 1 || node.Column > len(line) {
+		return "", false
+	}
+	output := line[:node.Column-1] + node.Value
+	if node.LineComment != "" {
+		output += " " + node.LineComment
+	}
+	if strings.HasSuffix(line, "\n") {
+		output += "\n"
+	}
+	return output, true
+}
+
+func isRootEntry(mapping, node *yaml.Node) bool {
+	for _, n := range mapping.Content {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+func isSameContent(content []byte, node *yaml.Node) bool {
+	var expected, actual interface{}
+	if err := node.Decode(&expected); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal(content, &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+func getIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isDeeper(line string, indent int) bool {
+	return strings.TrimSpace(line) != "" && getIndent(line) > indent
+}
+
+func walkNode(node *yaml.Node, fn func(*yaml.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	for _, n := range node.Content {
+		walkNode(n, fn)
+	}
+}
+
+func migrateContent(content []byte, output *Recipe) error {
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(content, node); err != nil {
+		return err
+	}
+	if getDocumentMapping(node) == nil {
+		return nil
+	}
+	if _, err := Migrations.Migrate(node); err != nil {
+		return err
+	}
+	*output = Recipe{}
+	return node.Decode(output)
+}
+
+// migrateLegacy migrates an unversioned recipe. The format of schema and procedure
+// used to be written in the recipe, but it has never been read.
+func migrateLegacy(mapping *yaml.Node) error {
+	frameworks := getMappingValue(mapping, "frameworks")
+	if frameworks == nil || frameworks.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, framework := range frameworks.Content {
+		for _, key := range []string{"schemas", "procedures"} {
+			entries := getMappingValue(framework, key)
+			if entries == nil || entries.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, entry := range entries.Content {
+				removeMappingKey(entry, "format")
+			}
+		}
+	}
+	return nil
+}
+
+func getDocumentMapping(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+func getAPIVersion(mapping *yaml.Node) string {
+	value := getMappingValue(mapping, apiVersionKey)
+	if value == nil {
+		return LegacyAPIVersion
+	}
+	return strings.TrimSpace(value.Value)
+}
+
+func setAPIVersion(mapping *yaml.Node, version string) {
+	if value := getMappingValue(mapping, apiVersionKey); value != nil {
+		value.Value = version
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: apiVersionKey}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version}
+	if len(mapping.Content) > 0 {
+		// keep the leading comment of the document on top
+		key.HeadComment = mapping.Content[0].HeadComment
+		mapping.Content[0].HeadComment = ""
+	}
+	mapping.Content = append([]*yaml.Node{key, value}, mapping.Content...)
+}
+
+func getMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func removeMappingKey(mapping *yaml.Node, key string) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func getVersionName(version string) string {
+	if version == LegacyAPIVersion {
+		return "unversioned"
+	}
+	return version
+}
+
+func init() {
+	if err := Migrations.Register(LegacyAPIVersion, CurrentAPIVersion, migrateLegacy); err != nil {
+		panic(err)
+	}
+}
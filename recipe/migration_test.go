@@ -0,0 +1,208 @@
+package recipe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/mocks"
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/endec/yaml"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+func TestMigrationFactory(t *testing.T) {
+	t.Run("should return error if migrate is nil", func(t *testing.T) {
+		factory := recipe.NewMigrationFactory()
+
+		actualErr := factory.Register("v1", "v2", nil)
+
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return error if version is already registered", func(t *testing.T) {
+		factory := recipe.NewMigrationFactory()
+		fn := func(node *yamlv3.Node) error {
+			return nil
+		}
+		factory.Register("v1", "v2", fn)
+
+		actualErr := factory.Register("v1", "v2", fn)
+
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return error if version is not supported", func(t *testing.T) {
+		factory := recipe.NewMigrationFactory()
+		node := &yamlv3.Node{}
+		yamlv3.Unmarshal([]byte("apiVersion: v0\n"), node)
+
+		expectedErr := errors.New("apiVersion [v0] is not supported")
+
+		actualVersions, actualErr := factory.Migrate(node)
+
+		assert.Nil(t, actualVersions)
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should migrate through every registered version", func(t *testing.T) {
+		factory := recipe.NewMigrationFactory()
+		var migrated []string
+		factory.Register("", "v0", func(node *yamlv3.Node) error {
+			migrated = append(migrated, "unversioned")
+			return nil
+		})
+		factory.Register("v0", recipe.CurrentAPIVersion, func(node *yamlv3.Node) error {
+			migrated = append(migrated, "v0")
+			return nil
+		})
+		node := &yamlv3.Node{}
+		yamlv3.Unmarshal([]byte("resources: []\n"), node)
+
+		expectedVersions := []string{"v0", recipe.CurrentAPIVersion}
+
+		actualVersions, actualErr := factory.Migrate(node)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, expectedVersions, actualVersions)
+		assert.Equal(t, []string{"unversioned", "v0"}, migrated)
+	})
+}
+
+func TestMigrateContent(t *testing.T) {
+	t.Run("should return content as it is if it is already at current version", func(t *testing.T) {
+		content := []byte("apiVersion: v1\nresources: []\n")
+
+		actualContent, actualVersions, actualErr := recipe.MigrateContent(content)
+
+		assert.Nil(t, actualErr)
+		assert.Empty(t, actualVersions)
+		assert.Equal(t, content, actualContent)
+	})
+
+	t.Run("should migrate unversioned content while preserving comments and indentation", func(t *testing.T) {
+		content := []byte(`# valor recipe
+resources:
+- name: user_account
+  batch_size: 3 # new in v0.0.5
+frameworks:
+- name: evaluation
+  procedures:
+  - name: enrich
+    format: jsonnet
+    path: ./enrich.jsonnet
+`)
+
+		expectedContent := `# valor recipe
+apiVersion: v1
+resources:
+- name: user_account
+  batch_size: 3 # new in v0.0.5
+frameworks:
+- name: evaluation
+  procedures:
+  - name: enrich
+    path: ./enrich.jsonnet
+`
+
+		actualContent, actualVersions, actualErr := recipe.MigrateContent(content)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []string{recipe.CurrentAPIVersion}, actualVersions)
+		assert.Equal(t, expectedContent, string(actualContent))
+	})
+
+	t.Run("should keep blank lines and remove every line of the removed entry", func(t *testing.T) {
+		content := []byte(`resources:
+    - name: user_account
+
+frameworks:
+    - name: evaluation
+
+      # enrich the account
+      procedures:
+          - name: enrich
+            format: >-
+                jsonnet
+            path: ./enrich.jsonnet
+`)
+
+		expectedContent := `apiVersion: v1
+resources:
+    - name: user_account
+
+frameworks:
+    - name: evaluation
+
+      # enrich the account
+      procedures:
+          - name: enrich
+            path: ./enrich.jsonnet
+`
+
+		actualContent, actualVersions, actualErr := recipe.MigrateContent(content)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []string{recipe.CurrentAPIVersion}, actualVersions)
+		assert.Equal(t, expectedContent, string(actualContent))
+	})
+
+	t.Run("should rewrite content if removed entry shares its line with another entry", func(t *testing.T) {
+		content := []byte(`frameworks:
+- name: evaluation
+  procedures:
+  - format: jsonnet
+    name: enrich
+`)
+
+		expectedContent := `apiVersion: v1
+frameworks:
+  - name: evaluation
+    procedures:
+      - name: enrich
+`
+
+		actualContent, actualVersions, actualErr := recipe.MigrateContent(content)
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []string{recipe.CurrentAPIVersion}, actualVersions)
+		assert.Equal(t, expectedContent, string(actualContent))
+	})
+}
+
+func TestLoadMigration(t *testing.T) {
+	t.Run("should migrate unversioned recipe in memory", func(t *testing.T) {
+		reader := &mocks.Reader{}
+		reader.On("Read").Return(&model.Data{
+			Content: []byte(`
+resources:
+- name: user_account
+frameworks:
+- name: evaluation
+  procedures:
+  - name: enrich
+    format: jsonnet
+`),
+		}, nil)
+
+		actualRecipe, actualErr := recipe.Load(reader, yaml.NewDecode())
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, recipe.CurrentAPIVersion, actualRecipe.APIVersion)
+		assert.Equal(t, "enrich", actualRecipe.Frameworks[0].Procedures[0].Name)
+	})
+
+	t.Run("should return error if apiVersion is not supported", func(t *testing.T) {
+		reader := &mocks.Reader{}
+		reader.On("Read").Return(&model.Data{
+			Content: []byte("apiVersion: v9\nresources: []\n"),
+		}, nil)
+
+		actualRecipe, actualErr := recipe.Load(reader, yaml.NewDecode())
+
+		assert.Nil(t, actualRecipe)
+		assert.NotNil(t, actualErr)
+	})
+}
@@ -0,0 +1,198 @@
+package recipe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gojek/optimus-extension-valor/model"
+)
+
+// Normalize normalizes the case-insensitive fields of a recipe,
+// which are the enum fields format, type, treat_as, and symlink,
+// including the ones within the shared outputs and the defaults.
+// Other fields, such as name, path, and regex_pattern, are case-sensitive
+// and are left as they are.
+func Normalize(rcp *Recipe) {
+	if rcp == nil {
+		return
+	}
+	for _, outputRcp := range rcp.Outputs {
+		normalizeOutput(outputRcp)
+	}
+	normalizeDefaults(rcp.Defaults)
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		resourceRcp.Format = strings.ToLower(resourceRcp.Format)
+		resourceRcp.Type = strings.ToLower(resourceRcp.Type)
+		normalizeExplore(resourceRcp.Explore)
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		for _, schemaRcp := range frameworkRcp.Schemas {
+			if schemaRcp == nil {
+				continue
+			}
+			schemaRcp.Type = strings.ToLower(schemaRcp.Type)
+			normalizeOutput(schemaRcp.Output)
+		}
+		for _, definitionRcp := range frameworkRcp.Definitions {
+			if definitionRcp == nil {
+				continue
+			}
+			definitionRcp.Format = strings.ToLower(definitionRcp.Format)
+			definitionRcp.Type = strings.ToLower(definitionRcp.Type)
+			normalizeExplore(definitionRcp.Explore)
+			if definitionRcp.Function != nil {
+				definitionRcp.Function.Type = strings.ToLower(definitionRcp.Function.Type)
+			}
+		}
+		for _, procedureRcp := range frameworkRcp.Procedures {
+			if procedureRcp == nil {
+				continue
+			}
+			procedureRcp.Type = strings.ToLower(procedureRcp.Type)
+			normalizeOutput(procedureRcp.Output)
+		}
+	}
+}
+
+func normalizeDefaults(rcp *Defaults) {
+	if rcp == nil {
+		return
+	}
+	if rcp.Resource != nil {
+		rcp.Resource.Format = strings.ToLower(rcp.Resource.Format)
+		rcp.Resource.Type = strings.ToLower(rcp.Resource.Type)
+		normalizeExplore(rcp.Resource.Explore)
+	}
+	if rcp.Definition != nil {
+		rcp.Definition.Format = strings.ToLower(rcp.Definition.Format)
+		rcp.Definition.Type = strings.ToLower(rcp.Definition.Type)
+		normalizeExplore(rcp.Definition.Explore)
+	}
+	if rcp.Schema != nil {
+		rcp.Schema.Type = strings.ToLower(rcp.Schema.Type)
+		normalizeOutput(rcp.Schema.Output)
+	}
+	if rcp.Procedure != nil {
+		rcp.Procedure.Type = strings.ToLower(rcp.Procedure.Type)
+		normalizeOutput(rcp.Procedure.Output)
+	}
+	if rcp.Output != nil {
+		rcp.Output.TreatAs = strings.ToLower(rcp.Output.TreatAs)
+		normalizeTargets(rcp.Output.Targets)
+	}
+}
+
+func normalizeExplore(rcp *Explore) {
+	if rcp == nil {
+		return
+	}
+	rcp.Symlink = strings.ToLower(rcp.Symlink)
+}
+
+func normalizeOutput(rcp *Output) {
+	if rcp == nil {
+		return
+	}
+	rcp.TreatAs = strings.ToLower(rcp.TreatAs)
+	normalizeTargets(rcp.Targets)
+}
+
+func normalizeTargets(rcps []*Target) {
+	for _, t := range rcps {
+		if t == nil {
+			continue
+		}
+		t.Format = strings.ToLower(t.Format)
+		t.Type = strings.ToLower(t.Type)
+	}
+}
+
+// DetectLegacyCase detects fields whose value depends on the legacy behaviour,
+// where the whole recipe content was lowercased before being decoded.
+// It returns one warning for each of such field. A recipe that specifies apiVersion
+// is never lowercased, so it is not checked.
+func DetectLegacyCase(content []byte, decode model.Decode) ([]string, error) {
+	if decode == nil {
+		return nil, errors.New("decode is nil")
+	}
+	current := &Recipe{}
+	if err := decode(content, current); err != nil {
+		return nil, err
+	}
+	if current.APIVersion != LegacyAPIVersion {
+		return nil, nil
+	}
+	legacy := &Recipe{}
+	if err := decode(bytes.ToLower(content), legacy); err != nil {
+		return nil, nil
+	}
+	Normalize(current)
+	Normalize(legacy)
+
+	var fieldPaths []string
+	collectDifference("", reflect.ValueOf(current), reflect.ValueOf(legacy), &fieldPaths)
+	output := make([]string, len(fieldPaths))
+	for i, p := range fieldPaths {
+		output[i] = fmt.Sprintf("[%s] is now case-sensitive and differs from the previously lowercased value", p)
+	}
+	return output, nil
+}
+
+func collectDifference(prefix string, current, legacy reflect.Value, output *[]string) {
+	if current.Kind() == reflect.Ptr {
+		if current.IsNil() != legacy.IsNil() {
+			*output = append(*output, prefix)
+			return
+		}
+		if current.IsNil() {
+			return
+		}
+		collectDifference(prefix, current.Elem(), legacy.Elem(), output)
+		return
+	}
+	switch current.Kind() {
+	case reflect.Struct:
+		for i := 0; i < current.NumField(); i++ {
+			field := current.Type().Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			collectDifference(name, current.Field(i), legacy.Field(i), output)
+		}
+	case reflect.Slice:
+		if current.Len() != legacy.Len() {
+			*output = append(*output, prefix)
+			return
+		}
+		for i := 0; i < current.Len(); i++ {
+			collectDifference(fmt.Sprintf("%s[%d]", prefix, i), current.Index(i), legacy.Index(i), output)
+		}
+	case reflect.Map:
+		if !reflect.DeepEqual(current.Interface(), legacy.Interface()) {
+			*output = append(*output, prefix)
+		}
+	case reflect.String:
+		// variable placeholder did not exist before, so its case is ignored
+		currentValue := variablePattern.ReplaceAllStringFunc(current.String(), strings.ToLower)
+		if currentValue != legacy.String() {
+			*output = append(*output, prefix)
+		}
+	default:
+		if current.Interface() != legacy.Interface() {
+			*output = append(*output, prefix)
+		}
+	}
+}
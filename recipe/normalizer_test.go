@@ -0,0 +1,212 @@
+package recipe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/model"
+	"github.com/gojek/optimus-extension-valor/plugin/endec/yaml"
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("should not panic if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		assert.NotPanics(t, func() { recipe.Normalize(rcp) })
+	})
+
+	t.Run("should lowercase enum fields only", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:         "User_Account",
+					Format:       "JSON",
+					Type:         "Dir",
+					Path:         "./Example/Resource",
+					RegexPattern: "[A-Z]",
+					Explore: &recipe.Explore{
+						Symlink: "Follow",
+					},
+				},
+				nil,
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "Evaluation",
+					Schemas: []*recipe.Schema{
+						{
+							Name: "Rule",
+							Type: "FILE",
+							Path: "./Schema.json",
+							Output: &recipe.Output{
+								TreatAs: "ERROR",
+								Targets: []*recipe.Target{
+									{
+										Name:   "Std",
+										Format: "YAML",
+										Type:   "STD",
+									},
+								},
+							},
+						},
+					},
+					Definitions: []*recipe.Definition{
+						{
+							Name:   "Memberships",
+							Format: "Yaml",
+							Type:   "File",
+							Path:   "./Definition",
+							Function: &recipe.Function{
+								Type: "File",
+								Path: "./Construct.jsonnet",
+							},
+						},
+					},
+					Procedures: []*recipe.Procedure{
+						{
+							Name: "Enrich",
+							Type: "File",
+							Path: "./Enrich.jsonnet",
+						},
+					},
+				},
+			},
+		}
+
+		recipe.Normalize(rcp)
+
+		resourceRcp := rcp.Resources[0]
+		assert.Equal(t, "User_Account", resourceRcp.Name)
+		assert.Equal(t, "json", resourceRcp.Format)
+		assert.Equal(t, "dir", resourceRcp.Type)
+		assert.Equal(t, "./Example/Resource", resourceRcp.Path)
+		assert.Equal(t, "[A-Z]", resourceRcp.RegexPattern)
+		assert.Equal(t, "follow", resourceRcp.Explore.Symlink)
+		frameworkRcp := rcp.Frameworks[0]
+		assert.Equal(t, "file", frameworkRcp.Schemas[0].Type)
+		assert.Equal(t, "./Schema.json", frameworkRcp.Schemas[0].Path)
+		assert.Equal(t, "error", frameworkRcp.Schemas[0].Output.TreatAs)
+		assert.Equal(t, "Std", frameworkRcp.Schemas[0].Output.Targets[0].Name)
+		assert.Equal(t, "yaml", frameworkRcp.Schemas[0].Output.Targets[0].Format)
+		assert.Equal(t, "std", frameworkRcp.Schemas[0].Output.Targets[0].Type)
+		assert.Equal(t, "yaml", frameworkRcp.Definitions[0].Format)
+		assert.Equal(t, "file", frameworkRcp.Definitions[0].Type)
+		assert.Equal(t, "file", frameworkRcp.Definitions[0].Function.Type)
+		assert.Equal(t, "./Construct.jsonnet", frameworkRcp.Definitions[0].Function.Path)
+		assert.Equal(t, "file", frameworkRcp.Procedures[0].Type)
+		assert.Equal(t, "Enrich", frameworkRcp.Procedures[0].Name)
+	})
+
+	t.Run("should lowercase enum fields of shared outputs and defaults", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Outputs: map[string]*recipe.Output{
+				"Console": {
+					TreatAs: "Error",
+					Targets: []*recipe.Target{
+						{Name: "Std_Output", Type: "STD", Format: "YAML"},
+					},
+				},
+				"empty": nil,
+			},
+			Defaults: &recipe.Defaults{
+				Resource:   &recipe.ResourceDefaults{Format: "JSON", Type: "Dir", Explore: &recipe.Explore{Symlink: "Error"}},
+				Definition: &recipe.DefinitionDefaults{Format: "YAML", Type: "File"},
+				Schema: &recipe.SchemaDefaults{
+					Type:   "FILE",
+					Output: &recipe.Output{TreatAs: "Warning"},
+				},
+				Procedure: &recipe.ProcedureDefaults{Type: "File"},
+				Output: &recipe.OutputDefaults{
+					TreatAs: "Success",
+					Targets: []*recipe.Target{
+						{Name: "File_Output", Type: "FILE", Format: "Json", Path: "./Output"},
+					},
+				},
+			},
+		}
+
+		recipe.Normalize(rcp)
+
+		outputRcp := rcp.Outputs["Console"]
+		assert.Equal(t, "error", outputRcp.TreatAs)
+		assert.Equal(t, "Std_Output", outputRcp.Targets[0].Name)
+		assert.Equal(t, "std", outputRcp.Targets[0].Type)
+		assert.Equal(t, "yaml", outputRcp.Targets[0].Format)
+		defaultsRcp := rcp.Defaults
+		assert.Equal(t, "json", defaultsRcp.Resource.Format)
+		assert.Equal(t, "dir", defaultsRcp.Resource.Type)
+		assert.Equal(t, "error", defaultsRcp.Resource.Explore.Symlink)
+		assert.Equal(t, "yaml", defaultsRcp.Definition.Format)
+		assert.Equal(t, "file", defaultsRcp.Definition.Type)
+		assert.Equal(t, "file", defaultsRcp.Schema.Type)
+		assert.Equal(t, "warning", defaultsRcp.Schema.Output.TreatAs)
+		assert.Equal(t, "file", defaultsRcp.Procedure.Type)
+		assert.Equal(t, "success", defaultsRcp.Output.TreatAs)
+		assert.Equal(t, "file", defaultsRcp.Output.Targets[0].Type)
+		assert.Equal(t, "json", defaultsRcp.Output.Targets[0].Format)
+		assert.Equal(t, "./Output", defaultsRcp.Output.Targets[0].Path)
+	})
+}
+
+func TestDetectLegacyCase(t *testing.T) {
+	t.Run("should return nil and error if decode is nil", func(t *testing.T) {
+		var decode model.Decode = nil
+
+		actualWarnings, actualErr := recipe.DetectLegacyCase([]byte("resources: []"), decode)
+
+		assert.Nil(t, actualWarnings)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return nil and error if decode returns error", func(t *testing.T) {
+		decode := func(c []byte, v interface{}) error {
+			return errors.New("decode error")
+		}
+
+		actualWarnings, actualErr := recipe.DetectLegacyCase([]byte("resources: []"), decode)
+
+		assert.Nil(t, actualWarnings)
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return empty warnings if only enum fields contain uppercase", func(t *testing.T) {
+		content := []byte(`
+resources:
+- name: user_account
+  type: FILE
+  format: JSON
+  path: ./example/resource
+`)
+
+		actualWarnings, actualErr := recipe.DetectLegacyCase(content, yaml.NewDecode())
+
+		assert.Empty(t, actualWarnings)
+		assert.Nil(t, actualErr)
+	})
+
+	t.Run("should return warnings for case-sensitive fields that differ from legacy behaviour", func(t *testing.T) {
+		content := []byte(`
+Resources:
+- name: user_account
+  type: file
+  format: json
+  path: ./Example/resource
+  regex_pattern: "[a-z]"
+frameworks:
+- name: Evaluation
+`)
+
+		expectedWarnings := []string{
+			"[resources] is now case-sensitive and differs from the previously lowercased value",
+			"[frameworks[0].name] is now case-sensitive and differs from the previously lowercased value",
+		}
+
+		actualWarnings, actualErr := recipe.DetectLegacyCase(content, yaml.NewDecode())
+
+		assert.EqualValues(t, expectedWarnings, actualWarnings)
+		assert.Nil(t, actualErr)
+	})
+}
@@ -0,0 +1,49 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ResolveOutputRefs resolves output_ref of every schema and procedure into its output,
+// where each of them receives its own copy of the referenced output.
+// Error is returned if the referenced output is not defined under outputs.
+func ResolveOutputRefs(rcp *Recipe) error {
+	if rcp == nil {
+		return errors.New("recipe is nil")
+	}
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		for _, schemaRcp := range frameworkRcp.Schemas {
+			if schemaRcp == nil || schemaRcp.OutputRef == "" {
+				continue
+			}
+			output, err := getReferencedOutput(rcp.Outputs, schemaRcp.OutputRef)
+			if err != nil {
+				return err
+			}
+			schemaRcp.Output = output
+		}
+		for _, procedureRcp := range frameworkRcp.Procedures {
+			if procedureRcp == nil || procedureRcp.OutputRef == "" {
+				continue
+			}
+			output, err := getReferencedOutput(rcp.Outputs, procedureRcp.OutputRef)
+			if err != nil {
+				return err
+			}
+			procedureRcp.Output = output
+		}
+	}
+	return nil
+}
+
+func getReferencedOutput(outputs map[string]*Output, name string) (*Output, error) {
+	output := outputs[name]
+	if output == nil {
+		return nil, fmt.Errorf("output [%s] is not found", name)
+	}
+	return copyOutput(output), nil
+}
@@ -0,0 +1,73 @@
+package recipe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveOutputRefs(t *testing.T) {
+	t.Run("should return error if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		actualErr := recipe.ResolveOutputRefs(rcp)
+
+		assert.NotNil(t, actualErr)
+	})
+
+	t.Run("should return error if referenced output is not found", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "framework",
+					Procedures: []*recipe.Procedure{
+						{Name: "procedure", OutputRef: "unknown"},
+					},
+				},
+			},
+		}
+
+		expectedErr := errors.New("output [unknown] is not found")
+
+		actualErr := recipe.ResolveOutputRefs(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should resolve every reference into its own copy of the output", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Outputs: map[string]*recipe.Output{
+				"console": {
+					TreatAs: "error",
+					Targets: []*recipe.Target{
+						{Name: "std_output", Type: "std", Format: "yaml"},
+					},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "framework",
+					Schemas: []*recipe.Schema{
+						{Name: "schema", OutputRef: "console"},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "procedure", OutputRef: "console"},
+						{Name: "inline"},
+					},
+				},
+			},
+		}
+
+		actualErr := recipe.ResolveOutputRefs(rcp)
+
+		assert.Nil(t, actualErr)
+		framework := rcp.Frameworks[0]
+		assert.EqualValues(t, rcp.Outputs["console"], framework.Schemas[0].Output)
+		assert.EqualValues(t, rcp.Outputs["console"], framework.Procedures[0].Output)
+		assert.NotSame(t, framework.Schemas[0].Output, framework.Procedures[0].Output)
+		assert.Nil(t, framework.Procedures[1].Output)
+	})
+}
@@ -2,71 +2,163 @@ package recipe
 
 // Recipe is the main structure for storing recipe execution flow
 type Recipe struct {
-	Resources  []*Resource  `yaml:"resources" validate:"required"`
-	Frameworks []*Framework `yaml:"frameworks" validate:"required"`
+	APIVersion string             `json:"apiVersion" yaml:"apiVersion"`
+	Include    []string           `json:"include" yaml:"include"`
+	Resources  []*Resource        `json:"resources" yaml:"resources" validate:"required"`
+	Frameworks []*Framework       `json:"frameworks" yaml:"frameworks" validate:"required"`
+	Defaults   *Defaults          `json:"defaults" yaml:"defaults"`
+	Outputs    map[string]*Output `json:"outputs" yaml:"outputs"`
+	Jsonnet    *Jsonnet           `json:"jsonnet" yaml:"jsonnet"`
+
+	// Defaulted records the fields whose value is supplied by defaults
+	Defaulted []*DefaultedField `json:"-" yaml:"-"`
+}
+
+// Jsonnet is a recipe on how Jsonnet files, such as procedures, are evaluated
+type Jsonnet struct {
+	// LibraryPaths are the directories searched for an import that is not found relative to the importing file
+	LibraryPaths []string `json:"library_paths" yaml:"library_paths" validate:"dive,required"`
+	// PoolSize is the number of VMs evaluating concurrently, where zero means one VM for every CPU
+	PoolSize int `json:"pool_size" yaml:"pool_size" validate:"gte=0"`
 }
 
 // Resource is a recipe on how and where to read the actual Resource data
 type Resource struct {
-	Name           string   `yaml:"name" validate:"required"`
-	Format         string   `yaml:"format" validate:"required,oneof=json yaml"`
-	Type           string   `yaml:"type" validate:"required,oneof=dir file"`
-	RegexPattern   string   `yaml:"regex_pattern"`
-	Path           string   `yaml:"path" validate:"required"`
-	BatchSize      int      `yaml:"batch_size"`
-	FrameworkNames []string `yaml:"framework_names" validate:"required,min=1"`
+	Name           string   `json:"name" yaml:"name" validate:"required"`
+	Format         string   `json:"format" yaml:"format" validate:"required,oneof=json yaml"`
+	Type           string   `json:"type" yaml:"type" validate:"required,oneof=dir file"`
+	RegexPattern   string   `json:"regex_pattern" yaml:"regex_pattern"`
+	Path           string   `json:"path" yaml:"path" validate:"required"`
+	BatchSize      int      `json:"batch_size" yaml:"batch_size"`
+	Explore        *Explore `json:"explore" yaml:"explore"`
+	FrameworkNames []string `json:"framework_names" yaml:"framework_names" validate:"required,min=1"`
+	Tags           []string `json:"tags" yaml:"tags"`
+
+	// Origin is the path of the recipe file where this recipe is defined
+	Origin string `json:"-" yaml:"-"`
 }
 
 // Framework is a recipe on how and where to read the actual Framework data
 type Framework struct {
-	Name        string        `yaml:"name" validate:"required"`
-	Schemas     []*Schema     `yaml:"schemas"`
-	Definitions []*Definition `yaml:"definitions"`
-	Procedures  []*Procedure  `yaml:"procedures"`
+	Name        string        `json:"name" yaml:"name" validate:"required"`
+	Extends     string        `json:"extends" yaml:"extends"`
+	Tags        []string      `json:"tags" yaml:"tags"`
+	Schemas     []*Schema     `json:"schemas" yaml:"schemas"`
+	Definitions []*Definition `json:"definitions" yaml:"definitions"`
+	Procedures  []*Procedure  `json:"procedures" yaml:"procedures"`
+
+	// Origin is the path of the recipe file where this recipe is defined
+	Origin string `json:"-" yaml:"-"`
 }
 
 // Definition is a recipe on how and where to read the actual Definition data
 type Definition struct {
-	Name         string    `yaml:"name" validate:"required"`
-	Format       string    `yaml:"format" validate:"required,oneof=json yaml"`
-	Type         string    `yaml:"type" validate:"required,oneof=dir file"`
-	Path         string    `yaml:"path" validate:"required"`
-	RegexPattern string    `yaml:"regex_pattern"`
-	Function     *Function `yaml:"function"`
+	Name         string    `json:"name" yaml:"name" validate:"required"`
+	Format       string    `json:"format" yaml:"format" validate:"required,oneof=json yaml"`
+	Type         string    `json:"type" yaml:"type" validate:"required,oneof=dir file"`
+	Path         string    `json:"path" yaml:"path" validate:"required"`
+	RegexPattern string    `json:"regex_pattern" yaml:"regex_pattern"`
+	Explore      *Explore  `json:"explore" yaml:"explore"`
+	Function     *Function `json:"function" yaml:"function"`
+}
+
+// Explore defines how a path with type dir is explored
+type Explore struct {
+	Recursive bool   `json:"recursive" yaml:"recursive"`
+	MaxDepth  int    `json:"max_depth" yaml:"max_depth" validate:"gte=0"`
+	Symlink   string `json:"symlink" yaml:"symlink" validate:"omitempty,oneof=skip follow error"`
 }
 
 // Function is a recipe on how to construct a Definition
 type Function struct {
-	Type string `yaml:"type" validate:"required,oneof=dir file"`
-	Path string `yaml:"path" validate:"required"`
+	Type string `json:"type" yaml:"type" validate:"required,oneof=dir file"`
+	Path string `json:"path" yaml:"path" validate:"required"`
 }
 
 // Schema is a recipe on how and where to read the actual Schema data
 type Schema struct {
-	Name   string  `yaml:"name" validate:"required"`
-	Type   string  `yaml:"type" validate:"required,oneof=dir file"`
-	Path   string  `yaml:"path" validate:"required"`
-	Output *Output `yaml:"output"`
+	Name      string  `json:"name" yaml:"name" validate:"required"`
+	Type      string  `json:"type" yaml:"type" validate:"required,oneof=dir file"`
+	Path      string  `json:"path" yaml:"path" validate:"required"`
+	When      string  `json:"when" yaml:"when"`
+	Output    *Output `json:"output" yaml:"output"`
+	OutputRef string  `json:"output_ref" yaml:"output_ref"`
 }
 
 // Procedure is a recipe on how and where to read the actual Procedure data
 type Procedure struct {
-	Name   string  `yaml:"name" validate:"required"`
-	Type   string  `yaml:"type" validate:"required,oneof=dir file"`
-	Path   string  `yaml:"path" validate:"required"`
-	Output *Output `yaml:"output"`
+	Name      string   `json:"name" yaml:"name" validate:"required"`
+	Type      string   `json:"type" yaml:"type" validate:"required,oneof=dir file"`
+	Path      string   `json:"path" yaml:"path" validate:"required"`
+	Inputs    []string `json:"inputs" yaml:"inputs"`
+	When      string   `json:"when" yaml:"when"`
+	Output    *Output  `json:"output" yaml:"output"`
+	OutputRef string   `json:"output_ref" yaml:"output_ref"`
 }
 
 // Output defines how the last procedure output is written
 type Output struct {
-	TreatAs string    `yaml:"treat_as" validate:"required,oneof=info warning error success"`
-	Targets []*Target `yaml:"targets" validate:"required,min=1"`
+	TreatAs string    `json:"treat_as" yaml:"treat_as" validate:"required,oneof=info warning error success"`
+	Targets []*Target `json:"targets" yaml:"targets" validate:"required,min=1"`
 }
 
 // Target defines how an output is written to the targetted stream
 type Target struct {
-	Name   string `yaml:"name" validate:"required"`
-	Format string `yaml:"format" validate:"required,oneof=json yaml"`
-	Type   string `yaml:"type" validate:"required,eq=dir"`
-	Path   string `yaml:"path"`
+	Name   string `json:"name" yaml:"name" validate:"required"`
+	Format string `json:"format" yaml:"format" validate:"required,oneof=json yaml"`
+	// Type follows the registered writers, which are only file and std
+	Type string `json:"type" yaml:"type" validate:"required,oneof=file std"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// Defaults defines the default values of fields that are not set
+type Defaults struct {
+	Resource   *ResourceDefaults   `json:"resource" yaml:"resource"`
+	Definition *DefinitionDefaults `json:"definition" yaml:"definition"`
+	Schema     *SchemaDefaults     `json:"schema" yaml:"schema"`
+	Procedure  *ProcedureDefaults  `json:"procedure" yaml:"procedure"`
+	Output     *OutputDefaults     `json:"output" yaml:"output"`
+}
+
+// ResourceDefaults defines the default values for every Resource
+type ResourceDefaults struct {
+	Format         string   `json:"format" yaml:"format" validate:"omitempty,oneof=json yaml"`
+	Type           string   `json:"type" yaml:"type" validate:"omitempty,oneof=dir file"`
+	RegexPattern   string   `json:"regex_pattern" yaml:"regex_pattern"`
+	BatchSize      int      `json:"batch_size" yaml:"batch_size" validate:"gte=0"`
+	Explore        *Explore `json:"explore" yaml:"explore"`
+	FrameworkNames []string `json:"framework_names" yaml:"framework_names"`
+	Tags           []string `json:"tags" yaml:"tags"`
+}
+
+// DefinitionDefaults defines the default values for every Definition
+type DefinitionDefaults struct {
+	Format       string   `json:"format" yaml:"format" validate:"omitempty,oneof=json yaml"`
+	Type         string   `json:"type" yaml:"type" validate:"omitempty,oneof=dir file"`
+	RegexPattern string   `json:"regex_pattern" yaml:"regex_pattern"`
+	Explore      *Explore `json:"explore" yaml:"explore"`
+}
+
+// SchemaDefaults defines the default values for every Schema
+type SchemaDefaults struct {
+	Type   string  `json:"type" yaml:"type" validate:"omitempty,oneof=dir file"`
+	Output *Output `json:"output" yaml:"output"`
+}
+
+// ProcedureDefaults defines the default values for every Procedure
+type ProcedureDefaults struct {
+	Type   string  `json:"type" yaml:"type" validate:"omitempty,oneof=dir file"`
+	Output *Output `json:"output" yaml:"output"`
+}
+
+// OutputDefaults defines the default values for every Output
+type OutputDefaults struct {
+	TreatAs string    `json:"treat_as" yaml:"treat_as" validate:"omitempty,oneof=info warning error success"`
+	Targets []*Target `json:"targets" yaml:"targets"`
+}
+
+// DefaultedField is a field whose value is supplied by defaults
+type DefaultedField struct {
+	Key   string
+	Value string
 }
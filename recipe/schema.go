@@ -0,0 +1,233 @@
+package recipe
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	jsonSchemaDraft      = "http://json-schema.org/draft-07/schema#"
+	jsonSchemaDefinition = "#/definitions/"
+)
+
+// typeToDefaultsType maps a recipe type to the type of its defaults, where a field
+// that could be supplied by defaults is not required within the schema
+var typeToDefaultsType = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(Resource{}):   reflect.TypeOf(ResourceDefaults{}),
+	reflect.TypeOf(Definition{}): reflect.TypeOf(DefinitionDefaults{}),
+	reflect.TypeOf(Schema{}):     reflect.TypeOf(SchemaDefaults{}),
+	reflect.TypeOf(Procedure{}):  reflect.TypeOf(ProcedureDefaults{}),
+	reflect.TypeOf(Output{}):     reflect.TypeOf(OutputDefaults{}),
+}
+
+// GenerateJSONSchema generates JSON Schema of the recipe format.
+// The schema is generated from the Recipe struct, its yaml tags, and its validate tags,
+// so it always follows what is defined in this package.
+func GenerateJSONSchema() map[string]interface{} {
+	definitions := make(map[string]interface{})
+	output := buildObjectSchema(reflect.TypeOf(Recipe{}), definitions)
+	// a recipe file could only be a fragment included by another, so nothing is required
+	// at the root, where the required fields are validated once every file is loaded
+	delete(output, "required")
+	output["$schema"] = jsonSchemaDraft
+	output["title"] = "valor recipe"
+	output["definitions"] = definitions
+	return output
+}
+
+func buildObjectSchema(_type reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	defaultedNames := make(map[string]bool)
+	if defaultsType, ok := typeToDefaultsType[_type]; ok {
+		for _, name := range getFieldNames(defaultsType) {
+			defaultedNames[name] = true
+		}
+	}
+	properties := make(map[string]interface{})
+	var required []string
+	for i := 0; i < _type.NumField(); i++ {
+		field := _type.Field(i)
+		name := getFieldName(field)
+		if name == "" {
+			continue
+		}
+		property := buildTypeSchema(field.Type, definitions)
+		if applyValidateTag(property, field.Tag.Get("validate")) && !defaultedNames[name] {
+			required = append(required, name)
+		}
+		properties[name] = property
+	}
+	output := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		output["required"] = required
+	}
+	return output
+}
+
+func getFieldNames(_type reflect.Type) []string {
+	var output []string
+	for i := 0; i < _type.NumField(); i++ {
+		if name := getFieldName(_type.Field(i)); name != "" {
+			output = append(output, name)
+		}
+	}
+	return output
+}
+
+// getFieldName gets the name of an exported field as written in a recipe, or empty if it is not written
+func getFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func buildTypeSchema(_type reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	switch _type.Kind() {
+	case reflect.Ptr:
+		return buildTypeSchema(_type.Elem(), definitions)
+	case reflect.Struct:
+		name := _type.Name()
+		if _, ok := definitions[name]; !ok {
+			// reserved first to stop recursive type from being built indefinitely
+			definitions[name] = nil
+			definitions[name] = buildObjectSchema(_type, definitions)
+		}
+		return map[string]interface{}{
+			"$ref": jsonSchemaDefinition + name,
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": buildTypeSchema(_type.Elem(), definitions),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": buildTypeSchema(_type.Elem(), definitions),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// applyValidateTag applies the validate tag into the property schema,
+// and returns true if the property is required. The rules after dive
+// apply to every element of the property instead of the property itself.
+func applyValidateTag(property map[string]interface{}, tag string) bool {
+	var required bool
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		pair := strings.SplitN(rule, "=", 2)
+		var param string
+		if len(pair) == 2 {
+			param = pair[1]
+		}
+		switch pair[0] {
+		case "dive":
+			applyElementValidateTag(property, strings.Join(rules[i+1:], ","))
+			return required
+		case "required":
+			required = true
+		case "oneof":
+			var values []interface{}
+			for _, v := range strings.Fields(param) {
+				values = append(values, convertTagParam(property, v))
+			}
+			// enum in a recipe is normalized when loaded, so any case of the value is accepted,
+			// while the exact values are still listed for the editor to complete
+			if property["type"] == "string" {
+				property["anyOf"] = []interface{}{
+					map[string]interface{}{"enum": values},
+					map[string]interface{}{"pattern": buildCaseInsensitivePattern(strings.Fields(param))},
+				}
+			} else {
+				property["enum"] = values
+			}
+		case "eq":
+			property["const"] = convertTagParam(property, param)
+		case "min", "gte":
+			applyBoundary(property, param, "minimum", "minItems", "minLength")
+		case "max", "lte":
+			applyBoundary(property, param, "maximum", "maxItems", "maxLength")
+		}
+	}
+	return required
+}
+
+// applyElementValidateTag applies the validate tag into the schema of every element of an array or a map,
+// where a required string element should not be empty
+func applyElementValidateTag(property map[string]interface{}, tag string) {
+	element, ok := property["items"].(map[string]interface{})
+	if !ok {
+		element, ok = property["additionalProperties"].(map[string]interface{})
+	}
+	if !ok {
+		return
+	}
+	if applyValidateTag(element, tag) && element["type"] == "string" && element["minLength"] == nil {
+		element["minLength"] = 1
+	}
+}
+
+// buildCaseInsensitivePattern builds a pattern matching any of the values regardless of its case,
+// without relying on regex flags that are not supported by every JSON Schema validator
+func buildCaseInsensitivePattern(values []string) string {
+	alternatives := make([]string, len(values))
+	for i, v := range values {
+		var builder strings.Builder
+		for _, r := range v {
+			lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+			if lower == upper {
+				builder.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			builder.WriteString("[" + string(lower) + string(upper) + "]")
+		}
+		alternatives[i] = builder.String()
+	}
+	return "^(" + strings.Join(alternatives, "|") + ")$"
+}
+
+func applyBoundary(property map[string]interface{}, param, numberKey, arrayKey, stringKey string) {
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	switch property["type"] {
+	case "integer", "number":
+		property[numberKey] = value
+	case "array":
+		property[arrayKey] = value
+	case "string":
+		property[stringKey] = value
+	}
+}
+
+func convertTagParam(property map[string]interface{}, param string) interface{} {
+	if property["type"] == "integer" {
+		if value, err := strconv.Atoi(param); err == nil {
+			return value
+		}
+	}
+	return param
+}
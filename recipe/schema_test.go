@@ -0,0 +1,250 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	t.Run("should generate enum and required based on validate tag except for defaulted fields", func(t *testing.T) {
+		expectedRequired := []string{"name", "path"}
+		expectedFormatAnyOf := []interface{}{
+			map[string]interface{}{"enum": []interface{}{"json", "yaml"}},
+			map[string]interface{}{"pattern": "^([jJ][sS][oO][nN]|[yY][aA][mM][lL])$"},
+		}
+
+		actualSchema := recipe.GenerateJSONSchema()
+
+		definitions := actualSchema["definitions"].(map[string]interface{})
+		resource := definitions["Resource"].(map[string]interface{})
+		properties := resource["properties"].(map[string]interface{})
+		assert.Equal(t, expectedRequired, resource["required"])
+		assert.Equal(t, expectedFormatAnyOf, properties["format"].(map[string]interface{})["anyOf"])
+		assert.Equal(t, 1, properties["framework_names"].(map[string]interface{})["minItems"])
+		assert.NotContains(t, properties, "Origin")
+	})
+
+	t.Run("should apply the rules after dive to every element instead of the field", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		withoutLibraryPathsRecipe := map[string]interface{}{
+			"resources":  []interface{}{},
+			"frameworks": []interface{}{},
+			"jsonnet": map[string]interface{}{
+				"pool_size": 2,
+			},
+		}
+		emptyLibraryPathRecipe := map[string]interface{}{
+			"resources":  []interface{}{},
+			"frameworks": []interface{}{},
+			"jsonnet": map[string]interface{}{
+				"library_paths": []interface{}{""},
+			},
+		}
+
+		actualSchema := recipe.GenerateJSONSchema()
+		withoutLibraryPathsResult, withoutLibraryPathsErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(withoutLibraryPathsRecipe))
+		emptyLibraryPathResult, emptyLibraryPathErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(emptyLibraryPathRecipe))
+
+		definitions := actualSchema["definitions"].(map[string]interface{})
+		jsonnet := definitions["Jsonnet"].(map[string]interface{})
+		libraryPaths := jsonnet["properties"].(map[string]interface{})["library_paths"].(map[string]interface{})
+		assert.NotContains(t, jsonnet, "required")
+		assert.Equal(t, 1, libraryPaths["items"].(map[string]interface{})["minLength"])
+		assert.NoError(t, withoutLibraryPathsErr)
+		assert.True(t, withoutLibraryPathsResult.Valid(), withoutLibraryPathsResult.Errors())
+		assert.NoError(t, emptyLibraryPathErr)
+		assert.False(t, emptyLibraryPathResult.Valid())
+	})
+
+	t.Run("should generate schema that accepts a valid recipe and rejects an invalid one", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		validRecipe := map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name":            "user_account",
+					"type":            "dir",
+					"path":            "./example/resource",
+					"format":          "json",
+					"framework_names": []interface{}{"user_account_evaluation"},
+				},
+			},
+			"frameworks": []interface{}{
+				map[string]interface{}{
+					"name": "user_account_evaluation",
+					"procedures": []interface{}{
+						map[string]interface{}{
+							"name": "enrich",
+							"type": "file",
+							"path": "./example/procedure/enrich.jsonnet",
+							"output": map[string]interface{}{
+								"treat_as": "success",
+								"targets": []interface{}{
+									map[string]interface{}{
+										"name":   "std_output",
+										"type":   "std",
+										"format": "yaml",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		invalidRecipe := map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name":   "user_account",
+					"type":   "unknown",
+					"format": "json",
+				},
+			},
+			"frameworks": []interface{}{},
+			"unknown":    true,
+		}
+
+		validResult, validErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(validRecipe))
+		invalidResult, invalidErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(invalidRecipe))
+
+		assert.NoError(t, validErr)
+		assert.True(t, validResult.Valid(), validResult.Errors())
+		assert.NoError(t, invalidErr)
+		assert.False(t, invalidResult.Valid())
+	})
+
+	t.Run("should generate schema that accepts enum in any case like the loader does", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		mixedCaseRecipe := map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name":            "user_account",
+					"type":            "DIR",
+					"path":            "./example/resource",
+					"format":          "Json",
+					"framework_names": []interface{}{"user_account_evaluation"},
+				},
+			},
+			"frameworks": []interface{}{},
+		}
+		unknownRecipe := map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name":            "user_account",
+					"type":            "DIRS",
+					"path":            "./example/resource",
+					"format":          "json",
+					"framework_names": []interface{}{"user_account_evaluation"},
+				},
+			},
+			"frameworks": []interface{}{},
+		}
+
+		mixedCaseResult, mixedCaseErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(mixedCaseRecipe))
+		unknownResult, unknownErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(unknownRecipe))
+
+		assert.NoError(t, mixedCaseErr)
+		assert.True(t, mixedCaseResult.Valid(), mixedCaseResult.Errors())
+		assert.NoError(t, unknownErr)
+		assert.False(t, unknownResult.Valid())
+	})
+
+	t.Run("should generate schema that only accepts target type with a registered writer", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		newRecipe := func(targetType string) map[string]interface{} {
+			return map[string]interface{}{
+				"outputs": map[string]interface{}{
+					"console": map[string]interface{}{
+						"treat_as": "info",
+						"targets": []interface{}{
+							map[string]interface{}{
+								"name":   "target",
+								"type":   targetType,
+								"format": "yaml",
+								"path":   "./out",
+							},
+						},
+					},
+				},
+			}
+		}
+
+		for _, targetType := range []string{"file", "std"} {
+			actualResult, actualErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(newRecipe(targetType)))
+
+			assert.NoError(t, actualErr)
+			assert.True(t, actualResult.Valid(), targetType)
+		}
+		actualDirResult, actualDirErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(newRecipe("dir")))
+
+		assert.NoError(t, actualDirErr)
+		assert.False(t, actualDirResult.Valid())
+	})
+
+	t.Run("should generate schema that accepts a fragment only including other files", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		fragmentRecipe := map[string]interface{}{
+			"include": []interface{}{"./resources.yaml", "./frameworks.yaml"},
+		}
+
+		actualResult, actualErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(fragmentRecipe))
+
+		assert.NoError(t, actualErr)
+		assert.True(t, actualResult.Valid(), actualResult.Errors())
+	})
+
+	t.Run("should generate schema that accepts a recipe relying on defaults", func(t *testing.T) {
+		schemaLoader := gojsonschema.NewGoLoader(recipe.GenerateJSONSchema())
+		defaultedRecipe := map[string]interface{}{
+			"defaults": map[string]interface{}{
+				"resource": map[string]interface{}{
+					"type":            "dir",
+					"format":          "json",
+					"framework_names": []interface{}{"user_account_evaluation"},
+				},
+				"procedure": map[string]interface{}{
+					"type": "file",
+				},
+				"output": map[string]interface{}{
+					"treat_as": "success",
+					"targets": []interface{}{
+						map[string]interface{}{
+							"name":   "std_output",
+							"type":   "std",
+							"format": "yaml",
+						},
+					},
+				},
+			},
+			"outputs": map[string]interface{}{
+				"console": map[string]interface{}{},
+			},
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name": "user_account",
+					"path": "./example/resource",
+				},
+			},
+			"frameworks": []interface{}{
+				map[string]interface{}{
+					"name": "user_account_evaluation",
+					"procedures": []interface{}{
+						map[string]interface{}{
+							"name":       "enrich",
+							"path":       "./example/procedure/enrich.jsonnet",
+							"output_ref": "console",
+						},
+					},
+				},
+			},
+		}
+
+		actualResult, actualErr := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(defaultedRecipe))
+
+		assert.NoError(t, actualErr)
+		assert.True(t, actualResult.Valid(), actualResult.Errors())
+	})
+}
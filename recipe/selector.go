@@ -0,0 +1,77 @@
+package recipe
+
+// SelectByTags selects resources and their framework names based on tags.
+// A framework name of a resource is selected if the tags of the resource and the framework
+// combined contain at least one of tags, or tags is empty, and none of skipTags.
+// A resource without any selected framework name is removed from the recipe.
+func SelectByTags(rcp *Recipe, tags, skipTags []string) {
+	if rcp == nil || (len(tags) == 0 && len(skipTags) == 0) {
+		return
+	}
+	nameToFramework := getNameToFramework(rcp.Frameworks)
+	var resources []*Resource
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		var frameworkNames []string
+		for _, name := range resourceRcp.FrameworkNames {
+			combinedTags := resourceRcp.Tags
+			if frameworkRcp := nameToFramework[name]; frameworkRcp != nil {
+				combinedTags = append(append([]string{}, resourceRcp.Tags...), frameworkRcp.Tags...)
+			}
+			if isTagSelected(combinedTags, tags, skipTags) {
+				frameworkNames = append(frameworkNames, name)
+			}
+		}
+		if len(frameworkNames) == 0 {
+			continue
+		}
+		resourceRcp.FrameworkNames = frameworkNames
+		resources = append(resources, resourceRcp)
+	}
+	rcp.Resources = resources
+}
+
+// SelectUsedFrameworks removes the frameworks not used by any resource of the recipe.
+// A framework that only serves as the parent of another should be resolved beforehand,
+// since it is removed too if no resource uses it.
+func SelectUsedFrameworks(rcp *Recipe) {
+	if rcp == nil {
+		return
+	}
+	used := make(map[string]bool)
+	for _, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		for _, name := range resourceRcp.FrameworkNames {
+			used[name] = true
+		}
+	}
+	var frameworks []*Framework
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp != nil && used[frameworkRcp.Name] {
+			frameworks = append(frameworks, frameworkRcp)
+		}
+	}
+	rcp.Frameworks = frameworks
+}
+
+func isTagSelected(current, tags, skipTags []string) bool {
+	if containsAny(current, skipTags) {
+		return false
+	}
+	return len(tags) == 0 || containsAny(current, tags)
+}
+
+func containsAny(current, targets []string) bool {
+	for _, c := range current {
+		for _, t := range targets {
+			if c == t {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/gojek/optimus-extension-valor/recipe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectByTags(t *testing.T) {
+	newRecipe := func() *recipe.Recipe {
+		return &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "nightly_resource",
+					Tags:           []string{"nightly"},
+					FrameworkNames: []string{"fast_framework", "slow_framework"},
+				},
+				{
+					Name:           "untagged_resource",
+					FrameworkNames: []string{"fast_framework", "slow_framework"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "fast_framework",
+					Tags: []string{"fast"},
+				},
+				{
+					Name: "slow_framework",
+					Tags: []string{"slow"},
+				},
+			},
+		}
+	}
+
+	t.Run("should not change recipe if no tag is specified", func(t *testing.T) {
+		rcp := newRecipe()
+
+		recipe.SelectByTags(rcp, nil, nil)
+
+		assert.EqualValues(t, newRecipe(), rcp)
+	})
+
+	t.Run("should select based on tags of resource and framework combined", func(t *testing.T) {
+		rcp := newRecipe()
+
+		recipe.SelectByTags(rcp, []string{"nightly", "fast"}, nil)
+
+		assert.Len(t, rcp.Resources, 2)
+		assert.Equal(t, []string{"fast_framework", "slow_framework"}, rcp.Resources[0].FrameworkNames)
+		assert.Equal(t, []string{"fast_framework"}, rcp.Resources[1].FrameworkNames)
+		assert.Len(t, rcp.Frameworks, 2)
+	})
+
+	t.Run("should skip based on skip tags even if it is selected by tags", func(t *testing.T) {
+		rcp := newRecipe()
+
+		recipe.SelectByTags(rcp, []string{"nightly"}, []string{"slow"})
+
+		assert.Len(t, rcp.Resources, 1)
+		assert.Equal(t, "nightly_resource", rcp.Resources[0].Name)
+		assert.Equal(t, []string{"fast_framework"}, rcp.Resources[0].FrameworkNames)
+	})
+
+	t.Run("should remove resource without any selected framework name", func(t *testing.T) {
+		rcp := newRecipe()
+
+		recipe.SelectByTags(rcp, nil, []string{"nightly", "slow"})
+
+		assert.Len(t, rcp.Resources, 1)
+		assert.Equal(t, "untagged_resource", rcp.Resources[0].Name)
+		assert.Equal(t, []string{"fast_framework"}, rcp.Resources[0].FrameworkNames)
+	})
+}
+
+func TestSelectUsedFrameworks(t *testing.T) {
+	t.Run("should remove framework not used by any resource", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					FrameworkNames: []string{"used_framework"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{Name: "unused_framework"},
+				{Name: "used_framework"},
+			},
+		}
+
+		recipe.SelectUsedFrameworks(rcp)
+
+		assert.Len(t, rcp.Frameworks, 1)
+		assert.Equal(t, "used_framework", rcp.Frameworks[0].Name)
+	})
+}
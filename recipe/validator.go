@@ -1,12 +1,23 @@
 package recipe
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/gojek/optimus-extension-valor/model"
+
 	"github.com/go-playground/validator/v10"
 )
 
+const (
+	fileType = "file"
+	dirType  = "dir"
+)
+
 // Validate validates the recipe
 func Validate(rcp *Recipe) error {
 	if err := validator.New().Struct(rcp); err != nil {
@@ -15,23 +26,21 @@ func Validate(rcp *Recipe) error {
 	if err := validateAllResources(rcp.Resources); err != nil {
 		return err
 	}
-	return validateAllFrameworks(rcp.Frameworks)
+	if err := validateAllFrameworks(rcp.Frameworks); err != nil {
+		return err
+	}
+	return validateAllExtends(rcp.Frameworks)
 }
 
 func validateAllResources(rcps []*Resource) error {
-	nameEncountered := make(map[string]int)
+	nameToOrigins := make(map[string][]string)
 	for _, resourceRcp := range rcps {
 		if err := ValidateResource(resourceRcp); err != nil {
 			return err
 		}
-		nameEncountered[resourceRcp.Name]++
-	}
-	var duplicateNames []string
-	for name, count := range nameEncountered {
-		if count > 1 {
-			duplicateNames = append(duplicateNames, name)
-		}
+		nameToOrigins[resourceRcp.Name] = append(nameToOrigins[resourceRcp.Name], resourceRcp.Origin)
 	}
+	duplicateNames := getDuplicateNames(nameToOrigins)
 	if len(duplicateNames) > 0 {
 		return fmt.Errorf("duplicate resource recipe [%s]",
 			strings.Join(duplicateNames, ", "))
@@ -40,19 +49,14 @@ func validateAllResources(rcps []*Resource) error {
 }
 
 func validateAllFrameworks(rcps []*Framework) error {
-	nameEncountered := make(map[string]int)
+	nameToOrigins := make(map[string][]string)
 	for _, frameworkRcp := range rcps {
 		if err := ValidateFramework(frameworkRcp); err != nil {
 			return err
 		}
-		nameEncountered[frameworkRcp.Name]++
-	}
-	var duplicateNames []string
-	for name, count := range nameEncountered {
-		if count > 1 {
-			duplicateNames = append(duplicateNames, name)
-		}
+		nameToOrigins[frameworkRcp.Name] = append(nameToOrigins[frameworkRcp.Name], frameworkRcp.Origin)
 	}
+	duplicateNames := getDuplicateNames(nameToOrigins)
 	if len(duplicateNames) > 0 {
 		return fmt.Errorf("duplicate framework recipe [%s]",
 			strings.Join(duplicateNames, ", "))
@@ -60,6 +64,27 @@ func validateAllFrameworks(rcps []*Framework) error {
 	return nil
 }
 
+func getDuplicateNames(nameToOrigins map[string][]string) []string {
+	var output []string
+	for name, origins := range nameToOrigins {
+		if len(origins) < 2 {
+			continue
+		}
+		var nonEmptyOrigins []string
+		for _, o := range origins {
+			if o != "" {
+				nonEmptyOrigins = append(nonEmptyOrigins, o)
+			}
+		}
+		if len(nonEmptyOrigins) > 0 {
+			name = fmt.Sprintf("%s in %s", name, strings.Join(nonEmptyOrigins, ", "))
+		}
+		output = append(output, name)
+	}
+	sort.Strings(output)
+	return output
+}
+
 // ValidateResource validates the recipe for a Resource
 func ValidateResource(resourceRcp *Resource) error {
 	if err := validator.New().Struct(resourceRcp); err != nil {
@@ -75,3 +100,208 @@ func ValidateFramework(frameworkRcp *Framework) error {
 	}
 	return nil
 }
+
+// ValidateDeep validates the recipe along with the references within it,
+// such as framework names, paths, and regex patterns. Unlike Validate,
+// it reports all problems at once as a model.Error, where the key is the
+// location of the problem within the recipe. A framework that extends another
+// is validated along with the entries it inherits, without modifying the recipe.
+func ValidateDeep(rcp *Recipe) error {
+	outputError := &model.Error{}
+	if err := Validate(rcp); err != nil {
+		outputError.Add("recipe", err)
+	}
+	if rcp == nil {
+		return outputError
+	}
+	frameworkNames := make(map[string]bool)
+	for _, frameworkRcp := range rcp.Frameworks {
+		if frameworkRcp != nil {
+			frameworkNames[frameworkRcp.Name] = true
+		}
+	}
+	for i, resourceRcp := range rcp.Resources {
+		if resourceRcp == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("resources[%s]", getKey(resourceRcp.Name, i))
+		for j, frameworkName := range resourceRcp.FrameworkNames {
+			if !frameworkNames[frameworkName] {
+				outputError.Add(fmt.Sprintf("%s.framework_names[%d]", prefix, j),
+					fmt.Errorf("framework [%s] is not found", frameworkName),
+				)
+			}
+		}
+		validateDeepPath(outputError, prefix, resourceRcp.Path, resourceRcp.Type)
+		validateDeepRegex(outputError, prefix, resourceRcp.RegexPattern)
+	}
+	if rcp.Jsonnet != nil {
+		for i, libraryPath := range rcp.Jsonnet.LibraryPaths {
+			validateDeepDir(outputError, fmt.Sprintf("jsonnet.library_paths[%d]", i), libraryPath)
+		}
+	}
+	var outputNames []string
+	for name := range rcp.Outputs {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+	for _, name := range outputNames {
+		prefix := fmt.Sprintf("outputs[%s]", name)
+		if rcp.Outputs[name] == nil {
+			outputError.Add(prefix, errors.New("output is empty"))
+			continue
+		}
+		if err := validator.New().Struct(rcp.Outputs[name]); err != nil {
+			outputError.Add(prefix, err)
+		}
+	}
+	// frameworks are validated as resolved, so the entries inherited from the parent are validated too
+	frameworks, resolveErr := copyResolvedFrameworks(rcp.Frameworks)
+	resolved := resolveErr == nil
+	if !resolved {
+		frameworks = rcp.Frameworks
+	}
+	for i, frameworkRcp := range frameworks {
+		if frameworkRcp == nil {
+			continue
+		}
+		validateDeepFramework(outputError, fmt.Sprintf("frameworks[%s]", getKey(frameworkRcp.Name, i)), frameworkRcp, rcp.Outputs, resolved)
+	}
+	if outputError.Length() > 0 {
+		return outputError
+	}
+	return nil
+}
+
+func validateDeepFramework(outputError *model.Error, prefix string, frameworkRcp *Framework, outputs map[string]*Output, resolved bool) {
+	schemaNames := make(map[string][]string)
+	for i, schemaRcp := range frameworkRcp.Schemas {
+		if schemaRcp == nil {
+			continue
+		}
+		schemaPrefix := fmt.Sprintf("%s.schemas[%s]", prefix, getKey(schemaRcp.Name, i))
+		if err := validator.New().Struct(schemaRcp); err != nil {
+			outputError.Add(schemaPrefix, err)
+		}
+		validateDeepPath(outputError, schemaPrefix, schemaRcp.Path, schemaRcp.Type)
+		validateDeepOutputRef(outputError, schemaPrefix, schemaRcp.Output, schemaRcp.OutputRef, outputs)
+		schemaNames[schemaRcp.Name] = append(schemaNames[schemaRcp.Name], "")
+	}
+	if duplicateNames := getDuplicateNames(schemaNames); len(duplicateNames) > 0 {
+		outputError.Add(prefix+".schemas",
+			fmt.Errorf("duplicate schema recipe [%s]", strings.Join(duplicateNames, ", ")),
+		)
+	}
+
+	definitionNames := make(map[string][]string)
+	for i, definitionRcp := range frameworkRcp.Definitions {
+		if definitionRcp == nil {
+			continue
+		}
+		definitionPrefix := fmt.Sprintf("%s.definitions[%s]", prefix, getKey(definitionRcp.Name, i))
+		if err := validator.New().Struct(definitionRcp); err != nil {
+			outputError.Add(definitionPrefix, err)
+		}
+		validateDeepPath(outputError, definitionPrefix, definitionRcp.Path, definitionRcp.Type)
+		validateDeepRegex(outputError, definitionPrefix, definitionRcp.RegexPattern)
+		if definitionRcp.Function != nil {
+			functionPrefix := definitionPrefix + ".function"
+			functionPath := definitionRcp.Function.Path
+			// a function is a single file regardless of its type
+			validateDeepPath(outputError, functionPrefix, functionPath, fileType)
+		}
+		definitionNames[definitionRcp.Name] = append(definitionNames[definitionRcp.Name], "")
+	}
+	if duplicateNames := getDuplicateNames(definitionNames); len(duplicateNames) > 0 {
+		outputError.Add(prefix+".definitions",
+			fmt.Errorf("duplicate definition recipe [%s]", strings.Join(duplicateNames, ", ")),
+		)
+	}
+
+	procedureNames := make(map[string][]string)
+	for i, procedureRcp := range frameworkRcp.Procedures {
+		if procedureRcp == nil {
+			continue
+		}
+		procedurePrefix := fmt.Sprintf("%s.procedures[%s]", prefix, getKey(procedureRcp.Name, i))
+		if err := validator.New().Struct(procedureRcp); err != nil {
+			outputError.Add(procedurePrefix, err)
+		}
+		validateDeepPath(outputError, procedurePrefix, procedureRcp.Path, procedureRcp.Type)
+		validateDeepOutputRef(outputError, procedurePrefix, procedureRcp.Output, procedureRcp.OutputRef, outputs)
+		// procedures of a framework that extends another are only known once the extends is resolved
+		if resolved || frameworkRcp.Extends == "" {
+			validateDeepInputs(outputError, procedurePrefix, procedureRcp.Inputs, procedureNames)
+		}
+		procedureNames[procedureRcp.Name] = append(procedureNames[procedureRcp.Name], "")
+	}
+	if duplicateNames := getDuplicateNames(procedureNames); len(duplicateNames) > 0 {
+		outputError.Add(prefix+".procedures",
+			fmt.Errorf("duplicate procedure recipe [%s]", strings.Join(duplicateNames, ", ")),
+		)
+	}
+}
+
+func validateDeepInputs(outputError *model.Error, prefix string, inputs []string, earlierNames map[string][]string) {
+	for _, input := range inputs {
+		if _, ok := earlierNames[input]; !ok {
+			outputError.Add(fmt.Sprintf("%s.inputs[%s]", prefix, input),
+				fmt.Errorf("input [%s] does not refer to an earlier procedure", input),
+			)
+		}
+	}
+}
+
+// validateDeepPath validates that the path of a local type exists and matches its type,
+// where a path with type file should not be a directory and a path with type dir should be one
+func validateDeepPath(outputError *model.Error, prefix, path, _type string) {
+	if path == "" || (_type != fileType && _type != dirType) {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		outputError.Add(prefix+".path", fmt.Errorf("path [%s] does not exist", path))
+		return
+	}
+	if _type == fileType && info.IsDir() {
+		outputError.Add(prefix+".path", fmt.Errorf("path [%s] is a directory, while type is [%s]", path, fileType))
+	}
+	if _type == dirType && !info.IsDir() {
+		outputError.Add(prefix+".path", fmt.Errorf("path [%s] is not a directory, while type is [%s]", path, dirType))
+	}
+}
+
+func validateDeepDir(outputError *model.Error, prefix, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		outputError.Add(prefix+".path", fmt.Errorf("path [%s] does not exist", path))
+	} else if !info.IsDir() {
+		outputError.Add(prefix+".path", fmt.Errorf("path [%s] is not a directory", path))
+	}
+}
+
+func validateDeepOutputRef(outputError *model.Error, prefix string, output *Output, outputRef string, outputs map[string]*Output) {
+	if outputRef == "" {
+		return
+	}
+	if output != nil {
+		outputError.Add(prefix+".output_ref", errors.New("output and output_ref cannot be specified at the same time"))
+		return
+	}
+	if outputs[outputRef] == nil {
+		outputError.Add(prefix+".output_ref", fmt.Errorf("output [%s] is not found", outputRef))
+	}
+}
+
+func validateDeepRegex(outputError *model.Error, prefix, regexPattern string) {
+	if _, err := regexp.Compile(regexPattern); err != nil {
+		outputError.Add(prefix+".regex_pattern", err)
+	}
+}
+
+func getKey(name string, idx int) string {
+	if name == "" {
+		return fmt.Sprintf("%d", idx)
+	}
+	return name
+}
@@ -2,8 +2,12 @@ package recipe_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
 
+	"github.com/gojek/optimus-extension-valor/model"
 	"github.com/gojek/optimus-extension-valor/recipe"
 
 	"github.com/stretchr/testify/assert"
@@ -80,6 +84,40 @@ func TestValidate(t *testing.T) {
 		assert.EqualValues(t, expectedErr, actualErr)
 	})
 
+	t.Run("should return error with origin if recipe has duplicate resources from different files", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource1",
+					Format:         "yaml",
+					Type:           "file",
+					Path:           "./valor.yaml",
+					FrameworkNames: []string{"evaluate"},
+					Origin:         "valor.yaml",
+				},
+				{
+					Name:           "resource1",
+					Format:         "yaml",
+					Type:           "file",
+					Path:           "./valor.yaml",
+					FrameworkNames: []string{"evaluate"},
+					Origin:         "sub/extra.yaml",
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+				},
+			},
+		}
+
+		expectedErr := errors.New("duplicate resource recipe [resource1 in valor.yaml, sub/extra.yaml]")
+
+		actualErr := recipe.Validate(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
 	t.Run("should return error if one or more recipes are invalid", func(t *testing.T) {
 		rcp := &recipe.Recipe{
 			Resources: []*recipe.Resource{
@@ -135,6 +173,62 @@ func TestValidate(t *testing.T) {
 		assert.EqualValues(t, expectedErr, actualErr)
 	})
 
+	t.Run("should return error with origin if recipe has duplicate frameworks from different files", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "yaml",
+					Type:           "file",
+					Path:           "./valor.yaml",
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name:   "evaluate1",
+					Origin: "valor.yaml",
+				},
+				{
+					Name:   "evaluate1",
+					Origin: "sub/extra.yaml",
+				},
+			},
+		}
+
+		expectedErr := errors.New("duplicate framework recipe [evaluate1 in valor.yaml, sub/extra.yaml]")
+
+		actualErr := recipe.Validate(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
+	t.Run("should return error if framework extends contains cycle", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "yaml",
+					Type:           "file",
+					Path:           "./valor.yaml",
+					FrameworkNames: []string{"evaluate1"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name:    "evaluate1",
+					Extends: "evaluate1",
+				},
+			},
+		}
+
+		expectedErr := errors.New("framework recipe extends cycle [evaluate1 -> evaluate1]")
+
+		actualErr := recipe.Validate(rcp)
+
+		assert.EqualValues(t, expectedErr, actualErr)
+	})
+
 	t.Run("should return nil if no error is encountered", func(t *testing.T) {
 		rcp := &recipe.Recipe{
 			Resources: []*recipe.Resource{
@@ -204,3 +298,429 @@ func TestValidateFramework(t *testing.T) {
 		assert.Nil(t, actualErr)
 	})
 }
+
+func TestValidateDeep(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := path.Join(dirPath, "procedure.jsonnet")
+	if err := ioutil.WriteFile(filePath, []byte("{}"), os.ModePerm); err != nil {
+		panic(err)
+	}
+
+	t.Run("should return error if recipe is nil", func(t *testing.T) {
+		var rcp *recipe.Recipe = nil
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.Error(t, actualErr)
+	})
+
+	t.Run("should return all problems at once", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           path.Join(dirPath, "missing"),
+					RegexPattern:   "[a-",
+					FrameworkNames: []string{"evaluate", "unknown"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+					Schemas: []*recipe.Schema{
+						{Name: "rule", Type: "file", Path: path.Join(dirPath, "missing.json")},
+					},
+					Definitions: []*recipe.Definition{
+						{
+							Name:   "memberships",
+							Format: "json",
+							Type:   "dir",
+							Path:   dirPath,
+							Function: &recipe.Function{
+								Type: "file",
+								Path: dirPath,
+							},
+						},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath},
+						{Name: "enrich", Type: "file", Path: filePath},
+					},
+				},
+			},
+		}
+
+		expectedKeys := []string{
+			"resources[resource].framework_names[1]",
+			"resources[resource].path",
+			"resources[resource].regex_pattern",
+			"frameworks[evaluate].schemas[rule].path",
+			"frameworks[evaluate].definitions[memberships].function.path",
+			"frameworks[evaluate].procedures",
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		actualJSON := string(actualErr.(*model.Error).JSON())
+		assert.Equal(t, len(expectedKeys), actualErr.(*model.Error).Length())
+		for _, key := range expectedKeys {
+			assert.Contains(t, actualJSON, key)
+		}
+	})
+
+	t.Run("should return error if output reference is invalid", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Outputs: map[string]*recipe.Output{
+				"console": {
+					TreatAs: "success",
+					Targets: []*recipe.Target{
+						{Name: "std_output", Type: "std", Format: "yaml"},
+					},
+				},
+				"invalid": {},
+			},
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+					Schemas: []*recipe.Schema{
+						{Name: "rule", Type: "file", Path: filePath, OutputRef: "unknown"},
+					},
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath, OutputRef: "console"},
+						{
+							Name:      "summarize",
+							Type:      "file",
+							Path:      filePath,
+							OutputRef: "console",
+							Output: &recipe.Output{
+								TreatAs: "info",
+								Targets: []*recipe.Target{
+									{Name: "std_output", Type: "std", Format: "yaml"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		expectedKeys := []string{
+			"outputs[invalid]",
+			"frameworks[evaluate].schemas[rule].output_ref",
+			"frameworks[evaluate].procedures[summarize].output_ref",
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		actualJSON := string(actualErr.(*model.Error).JSON())
+		assert.Equal(t, len(expectedKeys), actualErr.(*model.Error).Length())
+		for _, key := range expectedKeys {
+			assert.Contains(t, actualJSON, key)
+		}
+	})
+
+	t.Run("should return error if input does not refer to an earlier procedure", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath, Inputs: []string{"summarize"}},
+						{Name: "summarize", Type: "file", Path: filePath, Inputs: []string{"enrich", "unknown"}},
+					},
+				},
+			},
+		}
+
+		expectedKeys := []string{
+			"frameworks[evaluate].procedures[enrich].inputs[summarize]",
+			"frameworks[evaluate].procedures[summarize].inputs[unknown]",
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		actualJSON := string(actualErr.(*model.Error).JSON())
+		assert.Equal(t, len(expectedKeys), actualErr.(*model.Error).Length())
+		for _, key := range expectedKeys {
+			assert.Contains(t, actualJSON, key)
+		}
+	})
+
+	t.Run("should validate the entries inherited from the parent framework", func(t *testing.T) {
+		missingPath := path.Join(dirPath, "missing.jsonnet")
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"child"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: missingPath},
+						{Name: "summarize", Type: "file", Path: filePath},
+						{Name: "summarize", Type: "file", Path: filePath},
+					},
+				},
+				{
+					Name:    "child",
+					Extends: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "report", Type: "file", Path: filePath, Inputs: []string{"enrich", "unknown"}},
+					},
+				},
+			},
+		}
+
+		expectedKeys := []string{
+			"frameworks[parent].procedures[enrich].path",
+			"frameworks[parent].procedures",
+			"frameworks[child].procedures[enrich].path",
+			"frameworks[child].procedures",
+			"frameworks[child].procedures[report].inputs[unknown]",
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		actualJSON := string(actualErr.(*model.Error).JSON())
+		assert.Equal(t, len(expectedKeys), actualErr.(*model.Error).Length())
+		for _, key := range expectedKeys {
+			assert.Contains(t, actualJSON, key)
+		}
+		assert.Len(t, rcp.Frameworks[1].Procedures, 1)
+	})
+
+	t.Run("should return error if child framework overrides the parent entry with duplicate names", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"child"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath},
+					},
+				},
+				{
+					Name:    "child",
+					Extends: "parent",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath},
+						{Name: "enrich", Type: "file", Path: filePath},
+					},
+				},
+			},
+		}
+
+		expectedKey := "frameworks[child].procedures"
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		assert.Equal(t, 1, actualErr.(*model.Error).Length())
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), expectedKey)
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), "duplicate procedure recipe [enrich]")
+	})
+
+	t.Run("should return error if path with type dir is a file", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           filePath,
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+				},
+			},
+		}
+
+		expectedKey := "resources[resource].path"
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		assert.Equal(t, 1, actualErr.(*model.Error).Length())
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), expectedKey)
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), "is not a directory")
+	})
+
+	t.Run("should return error if path with type file is a directory", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: dirPath},
+					},
+				},
+			},
+		}
+
+		expectedKey := "frameworks[evaluate].procedures[enrich].path"
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		assert.Equal(t, 1, actualErr.(*model.Error).Length())
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), expectedKey)
+		assert.Contains(t, string(actualErr.(*model.Error).JSON()), "is a directory")
+	})
+
+	t.Run("should only accept file as function path for every function type", func(t *testing.T) {
+		for _, functionType := range []string{"file", "dir"} {
+			newRecipe := func(functionPath string) *recipe.Recipe {
+				return &recipe.Recipe{
+					Resources: []*recipe.Resource{
+						{
+							Name:           "resource",
+							Format:         "json",
+							Type:           "dir",
+							Path:           dirPath,
+							FrameworkNames: []string{"evaluate"},
+						},
+					},
+					Frameworks: []*recipe.Framework{
+						{
+							Name: "evaluate",
+							Definitions: []*recipe.Definition{
+								{
+									Name:     "memberships",
+									Format:   "json",
+									Type:     "dir",
+									Path:     dirPath,
+									Function: &recipe.Function{Type: functionType, Path: functionPath},
+								},
+							},
+						},
+					},
+				}
+			}
+			expectedKey := "frameworks[evaluate].definitions[memberships].function.path"
+
+			actualFileErr := recipe.ValidateDeep(newRecipe(filePath))
+			actualDirErr := recipe.ValidateDeep(newRecipe(dirPath))
+			actualMissingErr := recipe.ValidateDeep(newRecipe(path.Join(dirPath, "missing.jsonnet")))
+
+			assert.Nil(t, actualFileErr, functionType)
+			for _, actualErr := range []error{actualDirErr, actualMissingErr} {
+				assert.IsType(t, &model.Error{}, actualErr, functionType)
+				assert.Equal(t, 1, actualErr.(*model.Error).Length(), functionType)
+				assert.Contains(t, string(actualErr.(*model.Error).JSON()), expectedKey, functionType)
+			}
+		}
+	})
+
+	t.Run("should return error if library path is not an existing directory", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+				},
+			},
+			Jsonnet: &recipe.Jsonnet{
+				LibraryPaths: []string{dirPath, filePath, path.Join(dirPath, "unknown")},
+			},
+		}
+
+		expectedKeys := []string{
+			"jsonnet.library_paths[1].path",
+			"jsonnet.library_paths[2].path",
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.IsType(t, &model.Error{}, actualErr)
+		actualJSON := string(actualErr.(*model.Error).JSON())
+		assert.Equal(t, len(expectedKeys), actualErr.(*model.Error).Length())
+		for _, key := range expectedKeys {
+			assert.Contains(t, actualJSON, key)
+		}
+	})
+
+	t.Run("should return nil if no error is encountered", func(t *testing.T) {
+		rcp := &recipe.Recipe{
+			Resources: []*recipe.Resource{
+				{
+					Name:           "resource",
+					Format:         "json",
+					Type:           "dir",
+					Path:           dirPath,
+					RegexPattern:   "[a-z]",
+					FrameworkNames: []string{"evaluate"},
+				},
+			},
+			Frameworks: []*recipe.Framework{
+				{
+					Name: "evaluate",
+					Procedures: []*recipe.Procedure{
+						{Name: "enrich", Type: "file", Path: filePath},
+					},
+				},
+			},
+		}
+
+		actualErr := recipe.ValidateDeep(rcp)
+
+		assert.Nil(t, actualErr)
+	})
+}
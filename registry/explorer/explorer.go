@@ -12,7 +12,8 @@ var Explorers = NewFactory()
 
 // Factory is a factory for Explorer
 type Factory struct {
-	typeToFn map[string]model.ExplorePath
+	typeToFn    map[string]model.ExplorePath
+	typeToNewFn map[string]model.NewExplorePath
 }
 
 // Register registers an explorer based on the type
@@ -20,24 +21,52 @@ func (f *Factory) Register(_type string, fn model.ExplorePath) error {
 	if fn == nil {
 		return errors.New("Explorer is nil")
 	}
-	if f.typeToFn[_type] != nil {
+	if f.isRegistered(_type) {
 		return fmt.Errorf("[%s] is already registered", _type)
 	}
 	f.typeToFn[_type] = fn
 	return nil
 }
 
+// RegisterWithOption registers an explorer that accepts option based on the type
+func (f *Factory) RegisterWithOption(_type string, fn model.NewExplorePath) error {
+	if fn == nil {
+		return errors.New("NewExplorePath is nil")
+	}
+	if f.isRegistered(_type) {
+		return fmt.Errorf("[%s] is already registered", _type)
+	}
+	f.typeToNewFn[_type] = fn
+	return nil
+}
+
 // Get gets an explorer based on a specified type
 func (f *Factory) Get(_type string) (model.ExplorePath, error) {
+	return f.GetWithOption(_type, nil)
+}
+
+// GetWithOption gets an explorer based on a specified type and option
+func (f *Factory) GetWithOption(_type string, option *model.ExploreOption) (model.ExplorePath, error) {
+	if newFn := f.typeToNewFn[_type]; newFn != nil {
+		return newFn(option), nil
+	}
 	if f.typeToFn[_type] == nil {
 		return nil, fmt.Errorf("[%s] is not registered", _type)
 	}
+	if option != nil {
+		return nil, fmt.Errorf("[%s] does not accept explore option", _type)
+	}
 	return f.typeToFn[_type], nil
 }
 
+func (f *Factory) isRegistered(_type string) bool {
+	return f.typeToFn[_type] != nil || f.typeToNewFn[_type] != nil
+}
+
 // NewFactory initializes factory Formatter
 func NewFactory() *Factory {
 	return &Factory{
-		typeToFn: make(map[string]model.ExplorePath),
+		typeToFn:    make(map[string]model.ExplorePath),
+		typeToNewFn: make(map[string]model.NewExplorePath),
 	}
 }
@@ -75,6 +75,89 @@ func (f *FactorySuite) TestGet() {
 	})
 }
 
+func (f *FactorySuite) TestRegisterWithOption() {
+	f.Run("should return error if fn is nil", func() {
+		factory := explorer.NewFactory()
+		_type := "dir"
+		var newFn model.NewExplorePath = nil
+
+		actualErr := factory.RegisterWithOption(_type, newFn)
+
+		f.NotNil(actualErr)
+	})
+
+	f.Run("should return error fn is already registered", func() {
+		factory := explorer.NewFactory()
+		_type := "dir"
+		factory.Register(_type, func(root string, filter func(string) bool) ([]string, error) {
+			return nil, nil
+		})
+
+		actualErr := factory.RegisterWithOption(_type, func(option *model.ExploreOption) model.ExplorePath {
+			return nil
+		})
+
+		f.NotNil(actualErr)
+	})
+
+	f.Run("should return nil if no error is found", func() {
+		factory := explorer.NewFactory()
+		_type := "dir"
+
+		actualErr := factory.RegisterWithOption(_type, func(option *model.ExploreOption) model.ExplorePath {
+			return func(root string, filter func(string) bool) ([]string, error) {
+				return nil, nil
+			}
+		})
+
+		f.Nil(actualErr)
+	})
+}
+
+func (f *FactorySuite) TestGetWithOption() {
+	f.Run("should return nil and error if _type is not found", func() {
+		factory := explorer.NewFactory()
+		_type := "dir"
+
+		actualFn, actualErr := factory.GetWithOption(_type, &model.ExploreOption{})
+
+		f.Nil(actualFn)
+		f.NotNil(actualErr)
+	})
+
+	f.Run("should return nil and error if option is set but _type does not accept option", func() {
+		factory := explorer.NewFactory()
+		_type := "file"
+		factory.Register(_type, func(root string, filter func(string) bool) ([]string, error) {
+			return nil, nil
+		})
+
+		actualFn, actualErr := factory.GetWithOption(_type, &model.ExploreOption{})
+
+		f.Nil(actualFn)
+		f.NotNil(actualErr)
+	})
+
+	f.Run("should return fn and nil if _type accepts option", func() {
+		factory := explorer.NewFactory()
+		_type := "dir"
+		var actualOption *model.ExploreOption
+		factory.RegisterWithOption(_type, func(option *model.ExploreOption) model.ExplorePath {
+			actualOption = option
+			return func(root string, filter func(string) bool) ([]string, error) {
+				return nil, nil
+			}
+		})
+		expectedOption := &model.ExploreOption{Recursive: true}
+
+		actualFn, actualErr := factory.GetWithOption(_type, expectedOption)
+
+		f.NotNil(actualFn)
+		f.Nil(actualErr)
+		f.Equal(expectedOption, actualOption)
+	})
+}
+
 func TestWriterFactorySuite(t *testing.T) {
 	suite.Run(t, &FactorySuite{})
 }